@@ -0,0 +1,144 @@
+// Команда reindex пересчитывает эмбеддинги, построенные не текущей моделью (или без записи о модели):
+// заметки с их фрагментами — моделью для документов, вопросы из истории чата — моделью для запросов.
+//
+// Работает пачками и выводит прогресс. Обработанные строки перестают считаться устаревшими,
+// поэтому прерванный запуск (в том числе по Ctrl+C) продолжается повторным запуском с того же места.
+// Флаг -after-id позволяет пропустить строки, которые стабильно завершаются ошибкой.
+//
+//	go run ./cmd/reindex -target notes -batch 100
+package main
+
+import (
+	"NeuroNest/internal/config"
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/handlers"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/service"
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func main() {
+	target := flag.String("target", "all", "Что переиндексировать: notes, chat или all")
+	batch := flag.Int("batch", 50, "Размер пачки")
+	afterID := flag.Uint("after-id", 0, "Начать со строк с ID больше указанного")
+	force := flag.Bool("force", false, "Пересчитать все эмбеддинги, а не только устаревшие")
+	dryRun := flag.Bool("dry-run", false, "Только посчитать устаревшие строки")
+	flag.Parse()
+
+	config.LoadEnv()
+	if err := service.Init(); err != nil {
+		log.Fatalf("AI-провайдер недоступен: %v", err)
+	}
+	db.ConnectDBPostgres()
+	db.AutoMigrateTables()
+	db.SetupVectorStorage()
+	service.SetEmbeddingCache(service.NewEmbeddingCache(config.EmbeddingCacheSize, db.EmbeddingCacheStore{}))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	docModel, _ := service.EmbeddingModel(service.EmbeddingDocument)
+	queryModel, _ := service.EmbeddingModel(service.EmbeddingQuery)
+
+	if *target == "notes" || *target == "all" {
+		staleNotes := func(q *gorm.DB) *gorm.DB {
+			if *force {
+				return q
+			}
+			return q.Where(`(notes.embedding_model IS DISTINCT FROM ?
+				OR NOT EXISTS (SELECT 1 FROM note_chunks WHERE note_chunks.note_id = notes.id)
+				OR EXISTS (SELECT 1 FROM note_chunks WHERE note_chunks.note_id = notes.id AND note_chunks.embedding_model IS DISTINCT FROM ?))`,
+				docModel, docModel)
+		}
+		log.Printf("Заметки: модель %s", docModel)
+		run(ctx, "notes", &models.Note{}, staleNotes, *afterID, *batch, *dryRun, func(id uint) error {
+			var note models.Note
+			if err := db.DB.First(&note, id).Error; err != nil {
+				return err
+			}
+			return handlers.EmbedNote(note)
+		})
+	}
+
+	if ctx.Err() == nil && (*target == "chat" || *target == "all") {
+		staleChat := func(q *gorm.DB) *gorm.DB {
+			if *force {
+				return q
+			}
+			return q.Where("chat_histories.embedding_model IS DISTINCT FROM ?", queryModel)
+		}
+		log.Printf("История чата: модель %s", queryModel)
+		run(ctx, "chat_histories", &models.ChatHistory{}, staleChat, *afterID, *batch, *dryRun, func(id uint) error {
+			var entry models.ChatHistory
+			if err := db.DB.First(&entry, id).Error; err != nil {
+				return err
+			}
+			return handlers.EmbedChatHistory(entry)
+		})
+	}
+}
+
+// run обходит устаревшие строки таблицы по возрастанию ID пачками по batch и вызывает reindex для каждой
+func run(ctx context.Context, table string, model interface{}, stale func(*gorm.DB) *gorm.DB, afterID uint, batch int, dryRun bool, reindex func(id uint) error) {
+	var total int64
+	if err := stale(db.DB.Model(model)).Where(table+".id > ?", afterID).Count(&total).Error; err != nil {
+		log.Fatalf("%s: ошибка подсчёта строк: %v", table, err)
+	}
+	log.Printf("%s: к переиндексации %d", table, total)
+	if dryRun || total == 0 {
+		return
+	}
+
+	started := time.Now()
+	lastID := afterID
+	var done, failed int64
+	for ctx.Err() == nil {
+		var ids []uint
+		if err := stale(db.DB.Model(model)).
+			Where(table+".id > ?", lastID).
+			Order(table+".id").
+			Limit(batch).
+			Pluck(table+".id", &ids).Error; err != nil {
+			log.Fatalf("%s: ошибка выборки пачки: %v", table, err)
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		for _, id := range ids {
+			if ctx.Err() != nil {
+				break
+			}
+			if err := reindex(id); err != nil {
+				failed++
+				log.Printf("%s: строка %d: %v", table, id, err)
+			} else {
+				done++
+			}
+			lastID = id
+		}
+
+		processed := done + failed
+		elapsed := time.Since(started)
+		rate := float64(processed) / elapsed.Seconds()
+		var eta time.Duration
+		if rate > 0 && total > processed {
+			eta = time.Duration(float64(total-processed)/rate) * time.Second
+		}
+		log.Printf("%s: %d/%d (%.1f%%), ошибок %d, %.1f строк/с, осталось ~%s, последний ID %d",
+			table, processed, total, 100*float64(processed)/float64(total), failed, rate, eta.Round(time.Second), lastID)
+	}
+
+	if ctx.Err() != nil {
+		log.Printf("%s: прервано, для продолжения запустите команду снова (или с -after-id %d)", table, lastID)
+		return
+	}
+	log.Printf("%s: готово за %s, переиндексировано %d, ошибок %d", table, time.Since(started).Round(time.Second), done, failed)
+}
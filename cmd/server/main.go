@@ -4,8 +4,13 @@ import (
 	_ "NeuroNest/docs"
 	"NeuroNest/internal/config"
 	"NeuroNest/internal/db"
+	"NeuroNest/internal/handlers"
 	"NeuroNest/internal/router"
+	"NeuroNest/internal/service"
+	"NeuroNest/internal/trash"
+	"NeuroNest/internal/worker"
 	"log"
+	"time"
 )
 
 // @Title						---
@@ -15,8 +20,22 @@ import (
 func main() {
 	config.LoadEnv()
 
+	if err := service.Init(); err != nil {
+		log.Printf("AI-функции недоступны: %v", err)
+	}
+
 	db.ConnectDBPostgres()
 	db.AutoMigrateTables()
+	db.SetupVectorStorage()
+	db.SetupFullTextSearch()
+	service.SetEmbeddingCache(service.NewEmbeddingCache(config.EmbeddingCacheSize, db.EmbeddingCacheStore{}))
+
+	handlers.RegisterAIJobs()
+	handlers.EnqueueUnchunkedNotes()
+	handlers.BackfillContentHashes()
+	handlers.SplitLegacyTagPaths()
+	worker.Start(config.AIWorkerCount, time.Duration(config.AIJobPollSeconds)*time.Second)
+	trash.StartPurger(time.Duration(config.TrashPurgeInterval) * time.Minute)
 
 	r := router.RouterConfig()
 	if err := r.Run(":8080"); err != nil {
@@ -15,6 +15,43 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/ai/embedding-cache/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает число попаданий и промахов кэша эмбеддингов с момента запуска сервера и размер кэша.\nСтатистика общая для всех пользователей, поэтому доступна только администраторам",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai"
+                ],
+                "summary": "Статистика кэша эмбеддингов",
+                "responses": {
+                    "200": {
+                        "description": "Статистика кэша",
+                        "schema": {
+                            "$ref": "#/definitions/response.EmbeddingCacheStatsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Пользователь не администратор FORBIDDEN",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/auth/login": {
             "post": {
                 "description": "Авторизация пользователя и получение токенов",
@@ -235,6 +272,159 @@ const docTemplate = `{
                 }
             }
         },
+        "/chat": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Находит наиболее релевантные заметки по эмбеддингу вопроса, передаёт их YandexGPT как контекст и возвращает ответ со ссылками на заметки",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "chat"
+                ],
+                "summary": "Вопрос к заметкам (RAG)",
+                "parameters": [
+                    {
+                        "description": "Вопрос",
+                        "name": "chat",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ChatInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ответ модели. При Accept: text/event-stream — события delta с фрагментами и done с результатом",
+                        "schema": {
+                            "$ref": "#/definitions/response.ChatResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации VALIDATION_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов к модели AI_RATE_LIMITED",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка генерации эмбеддинга EMBEDDING_ERROR, ошибка генерации ответа CHAT_ERROR, ошибка БД DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Модель недоступна AI_UNAVAILABLE",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Модель не ответила вовремя AI_TIMEOUT",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/chat/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает диалоги пользователя с заметками, от последних к ранним",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chat"
+                ],
+                "summary": "Список диалогов",
+                "responses": {
+                    "200": {
+                        "description": "Список диалогов",
+                        "schema": {
+                            "$ref": "#/definitions/response.ChatConversationsListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении истории DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/chat/history/{conversationId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все обмены диалога в хронологическом порядке",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chat"
+                ],
+                "summary": "Сообщения диалога",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID диалога",
+                        "name": "conversationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сообщения диалога",
+                        "schema": {
+                            "$ref": "#/definitions/response.ChatMessagesListResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Диалог не найден CONVERSATION_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении истории DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/notes/create": {
             "post": {
                 "security": [
@@ -242,7 +432,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Создаёт новую заметку пользователя с генерацией эмбеддинга, тегами и вложениями",
+                "description": "Создаёт новую заметку пользователя с тегами и вложениями. Эмбеддинг и резюме ставятся в очередь и считаются в фоне. Если у пользователя уже есть заметки с тем же или почти тем же содержимым, их ID возвращаются в duplicate_ids",
                 "consumes": [
                     "multipart/form-data"
                 ],
@@ -288,6 +478,12 @@ const docTemplate = `{
                         "name": "tag_ids",
                         "in": "formData"
                     },
+                    {
+                        "type": "integer",
+                        "description": "ID темы",
+                        "name": "topic_id",
+                        "in": "formData"
+                    },
                     {
                         "type": "array",
                         "items": {
@@ -303,7 +499,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Заметка успешно создана",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "$ref": "#/definitions/response.NoteCreatedResponse"
                         }
                     },
                     "400": {
@@ -312,6 +508,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
+                    "422": {
+                        "description": "Несуществующие или чужие теги, связанные заметки или тема INVALID_REFERENCES",
+                        "schema": {
+                            "$ref": "#/definitions/response.InvalidReferencesResponse"
+                        }
+                    },
                     "500": {
                         "description": "Ошибка сервера",
                         "schema": {
@@ -321,14 +523,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/notes/list": {
+        "/notes/duplicates": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Выдаёт список всех заметок авторизованного пользователя",
+                "description": "Группирует неархивные заметки пользователя в кластеры дубликатов: с одинаковым содержимым (с точностью до пробелов) или со сходством эмбеддингов не ниже DUPLICATE_SIMILARITY. Заметки связываются в кластер транзитивно",
                 "consumes": [
                     "application/json"
                 ],
@@ -338,16 +540,16 @@ const docTemplate = `{
                 "tags": [
                     "note"
                 ],
-                "summary": "Получения списка заметок",
+                "summary": "Дубликаты заметок",
                 "responses": {
                     "200": {
-                        "description": "Список заметок",
+                        "description": "Кластеры дубликатов",
                         "schema": {
-                            "$ref": "#/definitions/response.NotesListResponse"
+                            "$ref": "#/definitions/response.DuplicatesResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при получении заметок: DB_ERROR",
+                        "description": "Ошибка при поиске дубликатов DB_ERROR",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -355,14 +557,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/notes/{id}": {
+        "/notes/list": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Получение заметки пользователя по id",
+                "description": "Выдаёт страницу заметок авторизованного пользователя с фильтрами и сортировкой. Total — общее число подходящих заметок",
                 "consumes": [
                     "application/json"
                 ],
@@ -372,38 +574,140 @@ const docTemplate = `{
                 "tags": [
                     "note"
                 ],
-                "summary": "Получения заметки",
+                "summary": "Получения списка заметок",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID заметки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Размер страницы (1-100, по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created",
+                            "updated",
+                            "title"
+                        ],
+                        "type": "string",
+                        "description": "Поле сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Направление сортировки",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "ID тегов",
+                        "name": "tag_ids",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "any — любой из тегов, all — все теги",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID темы",
+                        "name": "topic_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Учитывать заметки во вложенных темах (по умолчанию true)",
+                        "name": "with_subtopics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только архивные (true) или только активные (false)",
+                        "name": "archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Наличие вложений",
+                        "name": "has_attachments",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создана не раньше (YYYY-MM-DD)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создана не позже (YYYY-MM-DD)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Изменена не раньше (YYYY-MM-DD)",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Изменена не позже (YYYY-MM-DD)",
+                        "name": "updated_to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Заметка успешно получена",
+                        "description": "Список заметок",
                         "schema": {
-                            "$ref": "#/definitions/response.NoteResponse"
+                            "$ref": "#/definitions/response.NotesListResponse"
                         }
                     },
-                    "404": {
-                        "description": "Заметка не найдена NOTE_NOT_FOUND",
+                    "400": {
+                        "description": "Ошибка валидации VALIDATION_ERROR, неверный курсор INVALID_CURSOR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении заметок: DB_ERROR",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/notes/search": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаляет заметку пользователя по id и все связанные вложения",
+                "description": "Ищет по заголовку, содержимому и резюме с учётом морфологии русского и английского языков. Возвращает ранг и фрагменты с подсветкой \u003cmark\u003e: текст заметки в них HTML-экранирован, другой разметки нет",
                 "consumes": [
                     "application/json"
                 ],
@@ -413,31 +717,49 @@ const docTemplate = `{
                 "tags": [
                     "note"
                 ],
-                "summary": "Удаление заметки",
+                "summary": "Полнотекстовый поиск по заметкам",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID заметки",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Поисковый запрос (синтаксис websearch: кавычки для фраз, or, минус для исключения)",
+                        "name": "q",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальное число результатов (1-50, по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Искать также среди архивных заметок",
+                        "name": "include_archived",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Заметка успешно удалена",
+                        "description": "Найденные заметки",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "$ref": "#/definitions/response.TextSearchResponse"
                         }
                     },
-                    "404": {
-                        "description": "Заметка не найдена NOTE_NOT_FOUND",
+                    "400": {
+                        "description": "Ошибка валидации VALIDATION_ERROR",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при удалении заметки DB_ERROR",
+                        "description": "Ошибка БД DB_ERROR",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -445,14 +767,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/notes/{id}/archive": {
-            "patch": {
+        "/notes/search/hybrid": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Переносит заметку пользователя в архив (IsArchived = true)",
+                "description": "Объединяет полнотекстовый ранг и косинусное сходство эмбеддингов (RRF или взвешенная сумма). Для каждой заметки указано, какой сигнал сработал",
                 "consumes": [
                     "application/json"
                 ],
@@ -462,31 +784,77 @@ const docTemplate = `{
                 "tags": [
                     "note"
                 ],
-                "summary": "Архивировать заметку",
+                "summary": "Гибридный поиск по заметкам",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Поисковый запрос",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
                     {
                         "type": "integer",
-                        "description": "ID заметки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Максимальное число результатов (1-50, по умолчанию 10)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Искать также среди архивных заметок",
+                        "name": "include_archived",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "rrf",
+                            "weighted"
+                        ],
+                        "type": "string",
+                        "description": "Способ слияния (по умолчанию из HYBRID_METHOD)",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Вес полнотекстового сигнала для weighted (0..1)",
+                        "name": "text_weight",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Заметка архивирована",
+                        "description": "Найденные заметки",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "$ref": "#/definitions/response.HybridSearchResponse"
                         }
                     },
-                    "404": {
-                        "description": "Заметка не найдена NOTE_NOT_FOUND",
+                    "400": {
+                        "description": "Ошибка валидации VALIDATION_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов к модели AI_RATE_LIMITED",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при архивировании заметки DB_ERROR",
+                        "description": "Ошибка генерации эмбеддинга EMBEDDING_ERROR, ошибка БД DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Модель недоступна AI_UNAVAILABLE",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Модель не ответила вовремя AI_TIMEOUT",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -494,14 +862,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/notes/{id}/summarize": {
-            "post": {
+        "/notes/search/semantic": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Генерирует краткое резюме для заметки пользователя по её ID",
+                "description": "Строит эмбеддинг запроса и возвращает заметки пользователя, отсортированные по косинусному сходству лучшего фрагмента. Для каждой заметки указан этот фрагмент и его смещения",
                 "consumes": [
                     "application/json"
                 ],
@@ -511,31 +879,61 @@ const docTemplate = `{
                 "tags": [
                     "note"
                 ],
-                "summary": "Суммаризация заметки по ID",
+                "summary": "Семантический поиск по заметкам",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID заметки",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Поисковый запрос",
+                        "name": "q",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальное число результатов (1-50, по умолчанию 10)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Искать также среди архивных заметок",
+                        "name": "include_archived",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Резюме успешно сгенерировано",
+                        "description": "Найденные заметки с оценками",
                         "schema": {
-                            "$ref": "#/definitions/response.SummarizeResponse"
+                            "$ref": "#/definitions/response.SemanticSearchResponse"
                         }
                     },
-                    "404": {
-                        "description": "Заметка не найдена NOTE_NOT_FOUND",
+                    "400": {
+                        "description": "Ошибка валидации VALIDATION_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов к модели AI_RATE_LIMITED",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка генерации резюме SUMMARY_ERROR, Ошибка сохранения резюме SUMMARY_SAVE_ERROR",
+                        "description": "Ошибка генерации эмбеддинга EMBEDDING_ERROR, ошибка БД DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Модель недоступна AI_UNAVAILABLE",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Модель не ответила вовремя AI_TIMEOUT",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -543,14 +941,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/profile/delete-avatar": {
-            "delete": {
+        "/notes/trash": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаляет аватарку пользователя с сервера и очищает ссылку в базе данных.",
+                "description": "Возвращает удалённые заметки пользователя с датой окончательного удаления",
                 "consumes": [
                     "application/json"
                 ],
@@ -558,24 +956,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "profile"
+                    "trash"
                 ],
-                "summary": "Удаление аватарки пользователя",
+                "summary": "Корзина",
                 "responses": {
                     "200": {
-                        "description": "Аватарка успешно удалена",
-                        "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Аватарка не найдена",
+                        "description": "Заметки в корзине",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.TrashListResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка сервера",
+                        "description": "Ошибка при получении корзины DB_ERROR",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -583,14 +975,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/profile/get": {
+        "/notes/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Получает информацию о пользователе по его ID",
+                "description": "Получение заметки пользователя по id",
                 "consumes": [
                     "application/json"
                 ],
@@ -598,33 +990,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "profile"
+                    "note"
+                ],
+                "summary": "Получения заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Получение информации о профиле",
                 "responses": {
                     "200": {
-                        "description": "Информация о профиле пользователя",
+                        "description": "Заметка успешно получена",
                         "schema": {
-                            "$ref": "#/definitions/response.ProfileResponse"
+                            "$ref": "#/definitions/response.NoteResponse"
                         }
                     },
                     "404": {
-                        "description": "Пользователь не найден",
+                        "description": "Заметка не найдена NOTE_NOT_FOUND",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/profile/update": {
+            },
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Обновляет информацию профиля пользователя (кроме email)",
+                "description": "Частично обновляет заметку. При изменении содержимого ставит пересчёт эмбеддинга в очередь и помечает резюме устаревшим",
                 "consumes": [
                     "application/json"
                 ],
@@ -632,105 +1031,114 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "profile"
+                    "note"
                 ],
-                "summary": "Обновление информации профиля",
+                "summary": "Редактирование заметки",
                 "parameters": [
                     {
-                        "description": "Данные для обновления профиля",
-                        "name": "profile",
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "note",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.UpdateProfileInput"
+                            "$ref": "#/definitions/handlers.UpdateNoteInput"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Профиль успешно обновлен",
+                        "description": "Обновлённая заметка",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "$ref": "#/definitions/response.NoteResponse"
                         }
                     },
                     "400": {
-                        "description": "Ошибка валидации данных",
+                        "description": "Ошибка валидации VALIDATION_ERROR",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Пользователь не найден",
+                        "description": "Заметка не найдена NOTE_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Несуществующие или чужие теги или связанные заметки INVALID_REFERENCES",
+                        "schema": {
+                            "$ref": "#/definitions/response.InvalidReferencesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД DB_ERROR",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/profile/upload-avatar": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Позволяет пользователю загрузить аватарку. Поддерживаются форматы PNG, JPG, JPEG. Максимальный размер файла — 2MB.",
+                "description": "Перемещает заметку пользователя в корзину. Теги и вложения сохраняются до окончательного удаления",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "profile"
+                    "note"
                 ],
-                "summary": "Загрузка аватарки пользователя",
+                "summary": "Удаление заметки",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Аватарка пользователя",
-                        "name": "avatar",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Файл успешно загружен",
-                        "schema": {
-                            "$ref": "#/definitions/response.UploadAvatarResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Ошибка валидации (например, файл слишком большой или неподдерживаемый формат)",
+                        "description": "Заметка перемещена в корзину",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.SuccessResponse"
                         }
                     },
                     "404": {
-                        "description": "Пользователь не найден CODE: USER_NOT_FOUND",
+                        "description": "Заметка не найдена NOTE_NOT_FOUND",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка сервера (например, ошибка сохранения файла или базы данных)",
+                        "description": "Ошибка при удалении заметки DB_ERROR",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/tags/create": {
-            "post": {
+            },
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Создаёт новый тег",
+                "description": "Частично обновляет заметку. При изменении содержимого ставит пересчёт эмбеддинга в очередь и помечает резюме устаревшим",
                 "consumes": [
                     "application/json"
                 ],
@@ -738,72 +1146,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tag"
+                    "note"
                 ],
-                "summary": "Создать тег",
+                "summary": "Редактирование заметки",
                 "parameters": [
                     {
-                        "description": "Данные заметки",
-                        "name": "tag",
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "note",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.TagInput"
+                            "$ref": "#/definitions/handlers.UpdateNoteInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Тег успешно создан",
+                    "200": {
+                        "description": "Обновлённая заметка",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "$ref": "#/definitions/response.NoteResponse"
                         }
                     },
                     "400": {
-                        "description": "Ошибка валидации",
+                        "description": "Ошибка валидации VALIDATION_ERROR",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Ошибка при создании тега",
+                    "404": {
+                        "description": "Заметка не найдена NOTE_NOT_FOUND",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/tags/list": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Возвращает список тегов пользователя",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "tag"
-                ],
-                "summary": "Получить теги",
-                "responses": {
-                    "200": {
-                        "description": "Список тегов пользователя",
+                    },
+                    "422": {
+                        "description": "Несуществующие или чужие теги или связанные заметки INVALID_REFERENCES",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/response.TagsListResponse"
-                            }
+                            "$ref": "#/definitions/response.InvalidReferencesResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при получении тегов",
+                        "description": "Ошибка БД DB_ERROR",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -811,14 +1201,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/tags/{id}": {
-            "get": {
+        "/notes/{id}/archive": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Получает тег пользователя по id",
+                "description": "Переносит заметку пользователя в архив (IsArchived = true)",
                 "consumes": [
                     "application/json"
                 ],
@@ -826,13 +1216,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tag"
+                    "note"
                 ],
-                "summary": "Получение тега",
+                "summary": "Архивировать заметку",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID тега",
+                        "description": "ID заметки",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -840,26 +1230,34 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Полученный тег",
+                        "description": "Заметка архивирована",
                         "schema": {
-                            "$ref": "#/definitions/response.TagResponse"
+                            "$ref": "#/definitions/response.SuccessResponse"
                         }
                     },
                     "404": {
-                        "description": "Тег не найден TAG_NOT_FOUND",
+                        "description": "Заметка не найдена NOTE_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при архивировании заметки DB_ERROR",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/notes/{id}/merge": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаляет тег пользователя по id",
+                "description": "Вливает заметку source_id в заметку из пути: объединяет теги и связанные заметки, переносит все вложения, по желанию дописывает содержимое. Ссылки других заметок на source заменяются ссылками на целевую заметку, source перемещается в корзину",
                 "consumes": [
                     "application/json"
                 ],
@@ -867,208 +1265,2537 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tag"
+                    "note"
                 ],
-                "summary": "Удаление тега",
+                "summary": "Слияние заметок",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID тега",
+                        "description": "ID целевой заметки",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
+                    },
+                    {
+                        "description": "Вливаемая заметка",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MergeNotesInput"
+                        }
+                    }
+                ],
+                "responses": {
                     "200": {
-                        "description": "Тег успешно удалён",
+                        "description": "Целевая заметка после слияния",
+                        "schema": {
+                            "$ref": "#/definitions/response.NoteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации VALIDATION_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заметка не найдена NOTE_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notes/{id}/purge": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Безвозвратно удаляет заметку из корзины вместе с вложениями, их файлами и историей ревизий",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Окончательное удаление заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Заметка удалена окончательно",
                         "schema": {
                             "$ref": "#/definitions/response.SuccessResponse"
                         }
                     },
                     "404": {
-                        "description": "Тег не найден TAG_NOT_FOUND",
+                        "description": "Заметка не найдена в корзине NOTE_NOT_FOUND",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при удалении тега DB_ERROR",
+                        "description": "Ошибка при удалении заметки DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notes/{id}/related": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Объединяет связанные заметки, указанные пользователем (source=confirmed), с ближайшими по эмбеддингу (source=suggested). Сначала идут подтверждённые, затем предложенные по убыванию сходства. Несуществующие и чужие ID из related_ids пропускаются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "note"
+                ],
+                "summary": "Связанные заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Связанные заметки",
+                        "schema": {
+                            "$ref": "#/definitions/response.RelatedNotesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заметка не найдена NOTE_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении связанных заметок DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notes/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает удалённую заметку вместе с тегами и вложениями",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Восстановление заметки из корзины",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Заметка восстановлена",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заметка не найдена в корзине NOTE_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при восстановлении заметки DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notes/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список ревизий заметки от новых к старым (без содержимого)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revision"
+                ],
+                "summary": "История ревизий заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список ревизий",
+                        "schema": {
+                            "$ref": "#/definitions/response.RevisionsListResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заметка не найдена NOTE_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении ревизий DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notes/{id}/revisions/diff": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает unified diff заголовка, содержимого и резюме между двумя ревизиями",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revision"
+                ],
+                "summary": "Сравнение ревизий заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер исходной ревизии",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер конечной ревизии",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Diff между ревизиями",
+                        "schema": {
+                            "$ref": "#/definitions/response.RevisionDiffResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации VALIDATION_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заметка не найдена NOTE_NOT_FOUND, ревизия не найдена REVISION_NOT_FOUND",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.LoginInput": {
+        },
+        "/notes/{id}/revisions/{number}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает полный снимок заметки в указанной ревизии",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revision"
+                ],
+                "summary": "Получение ревизии заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер ревизии",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ревизия",
+                        "schema": {
+                            "$ref": "#/definitions/response.RevisionResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заметка не найдена NOTE_NOT_FOUND, ревизия не найдена REVISION_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notes/{id}/revisions/{number}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает заметке заголовок, содержимое и резюме из указанной ревизии и сохраняет результат как новую ревизию",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revision"
+                ],
+                "summary": "Восстановление ревизии заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер ревизии",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Восстановленная заметка",
+                        "schema": {
+                            "$ref": "#/definitions/response.NoteResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заметка не найдена NOTE_NOT_FOUND, ревизия не найдена REVISION_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notes/{id}/suggest-tags": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Подбирает теги из существующих тегов пользователя по сходству с заметками, уже помеченными каждым тегом, и по ответу языковой модели. Модель может предложить новые названия (is_new). Уверенность — среднее по доступным источникам. Теги, уже стоящие на заметке, не предлагаются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "Предложение тегов для заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимум предложений (1-20, по умолчанию 5)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Спрашивать языковую модель (по умолчанию true)",
+                        "name": "use_llm",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Предложенные теги",
+                        "schema": {
+                            "$ref": "#/definitions/response.TagSuggestionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации VALIDATION_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заметка не найдена NOTE_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов к модели AI_RATE_LIMITED",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка подбора тегов TAG_SUGGEST_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Модель недоступна AI_UNAVAILABLE",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Модель не ответила вовремя AI_TIMEOUT",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notes/{id}/summarize": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Генерирует краткое резюме для заметки пользователя по её ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "note"
+                ],
+                "summary": "Суммаризация заметки по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Резюме успешно сгенерировано. При Accept: text/event-stream — события delta с фрагментами и done с результатом",
+                        "schema": {
+                            "$ref": "#/definitions/response.SummarizeResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заметка не найдена NOTE_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Заметка изменилась во время генерации резюме NOTE_CHANGED",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов к модели AI_RATE_LIMITED",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка генерации резюме SUMMARY_ERROR, Ошибка сохранения резюме SUMMARY_SAVE_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Модель недоступна AI_UNAVAILABLE",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Модель не ответила вовремя AI_TIMEOUT",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notes/{id}/tags/{tagId}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ставит заметке тег пользователя. Повторная постановка того же тега ничего не меняет",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "Добавление тега заметке",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID тега",
+                        "name": "tagId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Заметка с обновлёнными тегами",
+                        "schema": {
+                            "$ref": "#/definitions/response.NoteResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заметка не найдена NOTE_NOT_FOUND, тег не найден TAG_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Убирает тег с заметки. Если тега на заметке нет, ничего не меняет",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "Снятие тега с заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID тега",
+                        "name": "tagId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Заметка с обновлёнными тегами",
+                        "schema": {
+                            "$ref": "#/definitions/response.NoteResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заметка не найдена NOTE_NOT_FOUND, тег не найден TAG_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notes/{id}/topic": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Перекладывает заметку в другую тему пользователя; topic_id = 0 убирает заметку из темы",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "note"
+                ],
+                "summary": "Перенос заметки в тему",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Тема",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MoveNoteTopicInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Заметка после переноса",
+                        "schema": {
+                            "$ref": "#/definitions/response.NoteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации VALIDATION_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Заметка не найдена NOTE_NOT_FOUND, тема не найдена TOPIC_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка БД DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/delete-avatar": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет аватарку пользователя с сервера и очищает ссылку в базе данных.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Удаление аватарки пользователя",
+                "responses": {
+                    "200": {
+                        "description": "Аватарка успешно удалена",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Аватарка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/get": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получает информацию о пользователе по его ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Получение информации о профиле",
+                "responses": {
+                    "200": {
+                        "description": "Информация о профиле пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProfileResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/update": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновляет информацию профиля пользователя (кроме email)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Обновление информации профиля",
+                "parameters": [
+                    {
+                        "description": "Данные для обновления профиля",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdateProfileInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Профиль успешно обновлен",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/upload-avatar": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Позволяет пользователю загрузить аватарку. Поддерживаются форматы PNG, JPG, JPEG. Максимальный размер файла — 2MB.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Загрузка аватарки пользователя",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Аватарка пользователя",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Файл успешно загружен",
+                        "schema": {
+                            "$ref": "#/definitions/response.UploadAvatarResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации (например, файл слишком большой или неподдерживаемый формат)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден CODE: USER_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера (например, ошибка сохранения файла или базы данных)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/create": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт новый тег. Имя вида work/clients/acme создаёт вложенный тег вместе с недостающими родителями. Пути тегов уникальны в пределах пользователя: при совпадении возвращается TAG_EXISTS с ID существующего тега, а с get_or_create=true — сам существующий тег со статусом 200",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "Создать тег",
+                "parameters": [
+                    {
+                        "description": "Данные тега",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TagInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Тег уже существует (get_or_create)",
+                        "schema": {
+                            "$ref": "#/definitions/response.TagCreatedResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Тег успешно создан",
+                        "schema": {
+                            "$ref": "#/definitions/response.TagCreatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Тег с таким именем уже существует TAG_EXISTS",
+                        "schema": {
+                            "$ref": "#/definitions/response.TagExistsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при создании тега",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список тегов пользователя с числом заметок и временем последнего использования. С tree=true в tags лежат теги верхнего уровня, вложенные — в children",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "Получить теги",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Вернуть теги деревом",
+                        "name": "tree",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список тегов пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/response.TagsListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении тегов",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получает тег пользователя по id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "Получение тега",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID тега",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Полученный тег",
+                        "schema": {
+                            "$ref": "#/definitions/response.TagResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Тег не найден TAG_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении тега DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет тег пользователя по id. Вложенные теги переходят к родителю удалённого тега",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "Удаление тега",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID тега",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Тег успешно удалён",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Тег не найден TAG_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Путь вложенного тега совпал с существующим TAG_EXISTS",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при удалении тега DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Переименовывает тег, переносит его к другому родителю, меняет описание, цвет или иконку.\nПути вложенных тегов обновляются вместе с ним",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "Изменение тега",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID тега",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdateTagInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновлённый тег",
+                        "schema": {
+                            "$ref": "#/definitions/response.TagResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации VALIDATION_ERROR, перенос тега внутрь себя TAG_CYCLE",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Тег или родитель не найден TAG_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Тег с таким путём уже существует TAG_EXISTS",
+                        "schema": {
+                            "$ref": "#/definitions/response.TagExistsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при обновлении тега DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}/merge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Переносит все заметки и вложенные теги тега из пути на тег target_id и удаляет исходный тег.\nВложенный тег, одноимённый с вложенным тегом target_id, сливается с ним так же рекурсивно",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "Слияние тегов",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вливаемого тега",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Тег, в который вливается исходный",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MergeTagInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Тег после слияния",
+                        "schema": {
+                            "$ref": "#/definitions/response.TagResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации VALIDATION_ERROR, слияние с собственным потомком TAG_CYCLE",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Тег не найден TAG_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при слиянии тегов DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/create": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт тему (блокнот) пользователя, при указании parent_id — вложенную",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topic"
+                ],
+                "summary": "Создать тему",
+                "parameters": [
+                    {
+                        "description": "Данные темы",
+                        "name": "topic",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TopicInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданная тема",
+                        "schema": {
+                            "$ref": "#/definitions/response.TopicResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации VALIDATION_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Родительская тема не найдена TOPIC_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Тема с таким названием уже есть TOPIC_EXISTS",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при создании темы DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все темы пользователя плоским списком",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topic"
+                ],
+                "summary": "Список тем",
+                "responses": {
+                    "200": {
+                        "description": "Темы пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/response.TopicsListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении тем DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/tree": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает темы пользователя деревом. note_count — заметки непосредственно в теме, total_note_count — вместе с вложенными темами. Заметки в корзине не считаются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topic"
+                ],
+                "summary": "Дерево тем",
+                "responses": {
+                    "200": {
+                        "description": "Дерево тем",
+                        "schema": {
+                            "$ref": "#/definitions/response.TopicTreeResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении тем DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает тему пользователя по id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topic"
+                ],
+                "summary": "Получение темы",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID темы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Тема",
+                        "schema": {
+                            "$ref": "#/definitions/response.TopicResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Тема не найдена TOPIC_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет тему. Вложенные темы и заметки (в том числе из корзины) переходят к родителю удалённой темы",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topic"
+                ],
+                "summary": "Удаление темы",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID темы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Тема удалена",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Тема не найдена TOPIC_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при удалении темы DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Переименовывает тему, меняет описание или переносит её вместе с вложенными темами и заметками под другую тему (parent_id = 0 — на верхний уровень)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topic"
+                ],
+                "summary": "Изменение темы",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID темы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "topic",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdateTopicInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновлённая тема",
+                        "schema": {
+                            "$ref": "#/definitions/response.TopicResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации VALIDATION_ERROR, перенос внутрь себя TOPIC_CYCLE",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Тема не найдена TOPIC_NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Тема с таким названием уже есть TOPIC_EXISTS",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при сохранении темы DB_ERROR",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.ChatInput": {
+            "type": "object",
+            "required": [
+                "question"
+            ],
+            "properties": {
+                "conversation_id": {
+                    "description": "Продолжение существующего диалога",
+                    "type": "string"
+                },
+                "include_archived": {
+                    "type": "boolean"
+                },
+                "question": {
+                    "type": "string",
+                    "example": "Что я писал про суммаризацию?"
+                },
+                "top_k": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1
+                }
+            }
+        },
+        "handlers.LoginInput": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "yi29jksA"
+                }
+            }
+        },
+        "handlers.MergeNotesInput": {
+            "type": "object",
+            "required": [
+                "source_id"
+            ],
+            "properties": {
+                "append_content": {
+                    "description": "Дописать содержимое source в конец; иначе остаётся содержимое целевой заметки",
+                    "type": "boolean"
+                },
+                "source_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.MergeTagInput": {
+            "type": "object",
+            "required": [
+                "target_id"
+            ],
+            "properties": {
+                "target_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.MoveNoteTopicInput": {
+            "type": "object",
+            "properties": {
+                "topic_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RegisterInput": {
+            "type": "object",
+            "required": [
+                "email",
+                "nickname",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "nickname": {
+                    "type": "string",
+                    "example": "user123"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "yi29jksA"
+                }
+            }
+        },
+        "handlers.TagInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "color": {
+                    "description": "#RRGGBB",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "get_or_create": {
+                    "description": "Вернуть существующий тег с таким путём вместо ошибки TAG_EXISTS",
+                    "type": "boolean"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Имя или путь через «/»: недостающие родительские теги создаются",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.TopicInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "description": "Родительская тема; не указана — тема верхнего уровня",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.UpdateNoteInput": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "related_ids": {
+                    "description": "Полностью заменяет список связанных заметок",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "tag_ids": {
+                    "description": "Полностью заменяет набор тегов",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.UpdateProfileInput": {
+            "type": "object",
+            "properties": {
+                "auto_tag_enabled": {
+                    "description": "Автоматически проставлять предложенные теги новым заметкам",
+                    "type": "boolean"
+                },
+                "auto_tag_threshold": {
+                    "description": "Порог уверенности 0..1",
+                    "type": "number",
+                    "maximum": 1
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "nickname": {
+                    "type": "string"
+                },
+                "profile_pic": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.UpdateTagInput": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "description": "#RRGGBB, пустая строка убирает цвет",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Имя без «/»: для переноса используется parent_id",
+                    "type": "string"
+                },
+                "parent_id": {
+                    "description": "0 — перенести на верхний уровень",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.UpdateTopicInput": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.AttachmentShort": {
+            "type": "object",
+            "properties": {
+                "file_size": {
+                    "type": "integer"
+                },
+                "file_type": {
+                    "type": "string"
+                },
+                "file_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.ChatCitation": {
+            "type": "object",
+            "properties": {
+                "note_id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.ChatConversationResponse": {
+            "type": "object",
+            "properties": {
+                "conversation_id": {
+                    "type": "string"
+                },
+                "last_message_at": {
+                    "type": "string"
+                },
+                "messages_count": {
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "Первый вопрос диалога",
+                    "type": "string"
+                }
+            }
+        },
+        "response.ChatConversationsListResponse": {
+            "type": "object",
+            "properties": {
+                "conversations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.ChatConversationResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.ChatMessagesListResponse": {
+            "type": "object",
+            "properties": {
+                "conversation_id": {
+                    "type": "string"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.ChatResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.ChatResponse": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "type": "string"
+                },
+                "citations": {
+                    "description": "Заметки, на которые опирается ответ",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.ChatCitation"
+                    }
+                },
+                "conversation_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "question": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.ChunkMatch": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "integer",
+                    "example": 2650
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.DuplicateCluster": {
+            "type": "object",
+            "properties": {
+                "exact": {
+                    "description": "У всех заметок одинаковое содержимое",
+                    "type": "boolean"
+                },
+                "notes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.DuplicateNote"
+                    }
+                },
+                "similarity": {
+                    "description": "Наименьшее сходство среди связей, объединивших кластер; 1 для точных дубликатов",
+                    "type": "number"
+                }
+            }
+        },
+        "response.DuplicateNote": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.DuplicatesResponse": {
+            "type": "object",
+            "properties": {
+                "clusters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.DuplicateCluster"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.EmbeddingCacheStatsResponse": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "hit_rate": {
+                    "description": "Доля попаданий, 0..1",
+                    "type": "number"
+                },
+                "hits": {
+                    "description": "Всего попаданий с момента запуска сервера",
+                    "type": "integer"
+                },
+                "memory_entries": {
+                    "description": "Записей в LRU",
+                    "type": "integer"
+                },
+                "memory_hits": {
+                    "description": "Попадания в LRU в памяти",
+                    "type": "integer"
+                },
+                "misses": {
+                    "description": "Эмбеддинг запрошен у модели",
+                    "type": "integer"
+                },
+                "store_hits": {
+                    "description": "Попадания в таблицу embedding_cache",
+                    "type": "integer"
+                },
+                "stored_entries": {
+                    "description": "Записей в таблице embedding_cache",
+                    "type": "integer"
+                }
+            }
+        },
+        "response.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.HybridSearchResponse": {
+            "type": "object",
+            "properties": {
+                "method": {
+                    "type": "string",
+                    "example": "rrf"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.HybridSearchResult"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.HybridSearchResult": {
+            "type": "object",
+            "properties": {
+                "chunk": {
+                    "description": "Фрагмент, совпавший по семантическому сигналу",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/response.ChunkMatch"
+                        }
+                    ]
+                },
+                "matched_by": {
+                    "description": "Сигналы, по которым найдена заметка",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "text",
+                        "semantic"
+                    ]
+                },
+                "note": {
+                    "$ref": "#/definitions/response.NoteResponse"
+                },
+                "score": {
+                    "description": "Итоговая оценка после слияния",
+                    "type": "number"
+                },
+                "semantic_score": {
+                    "description": "Косинусное сходство с запросом",
+                    "type": "number"
+                },
+                "snippet": {
+                    "description": "Фрагменты текста с подсветкой \u003cmark\u003e, HTML-экранированы",
+                    "type": "string"
+                },
+                "text_rank": {
+                    "description": "Ранг полнотекстового поиска",
+                    "type": "number"
+                },
+                "title_highlight": {
+                    "description": "Заголовок с подсветкой совпадений, HTML-экранирован",
+                    "type": "string"
+                }
+            }
+        },
+        "response.InvalidReferencesResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "INVALID_REFERENCES"
+                },
+                "invalid_related_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "invalid_tag_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "invalid_topic_id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.NoteCreatedResponse": {
+            "type": "object",
+            "properties": {
+                "duplicate_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.NoteResponse": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.AttachmentShort"
+                    }
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "duplicate_ids": {
+                    "description": "Точные и почти дубликаты на момент последнего расчёта эмбеддинга",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "embedding_status": {
+                    "description": "Фоновый расчёт эмбеддинга: pending, processing, ready, failed",
+                    "type": "string",
+                    "example": "ready"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_archived": {
+                    "type": "boolean"
+                },
+                "related_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "summary_stale": {
+                    "description": "Резюме не соответствует текущему содержимому",
+                    "type": "boolean"
+                },
+                "summary_status": {
+                    "description": "Фоновая генерация резюме: pending, processing, ready, failed",
+                    "type": "string",
+                    "example": "ready"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.TagShort"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "topic_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.NotesListResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "description": "Курсор следующей страницы, пусто на последней",
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.NoteResponse"
+                    }
+                },
+                "total": {
+                    "description": "Общее число заметок, подходящих под фильтры",
+                    "type": "integer"
+                }
+            }
+        },
+        "response.ProfileResponse": {
+            "type": "object",
+            "properties": {
+                "auto_tag_enabled": {
+                    "description": "Автоматически проставлять предложенные теги",
+                    "type": "boolean"
+                },
+                "auto_tag_threshold": {
+                    "description": "Минимальная уверенность для автоматического проставления",
+                    "type": "number"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "nickname": {
+                    "type": "string"
+                },
+                "profile_pic": {
+                    "description": "Ссылка на фото профиля",
+                    "type": "string"
+                }
+            }
+        },
+        "response.RelatedNote": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "Сходство эмбеддингов, если заметка среди ближайших соседей",
+                    "type": "number"
+                },
+                "source": {
+                    "description": "confirmed, suggested",
+                    "type": "string",
+                    "example": "suggested"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.RelatedNotesResponse": {
+            "type": "object",
+            "properties": {
+                "note_id": {
+                    "type": "integer"
+                },
+                "related": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.RelatedNote"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.RevisionDiffResponse": {
+            "type": "object",
+            "properties": {
+                "diff": {
+                    "description": "Unified diff по заголовку, содержимому и резюме",
+                    "type": "string"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.RevisionResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "updated"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "summary_stale": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.RevisionsListResponse": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
             "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "user@example.com"
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.RevisionResponse"
+                    }
                 },
-                "password": {
-                    "type": "string",
-                    "example": "yi29jksA"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "handlers.RefreshTokenRequest": {
+        "response.SemanticSearchResponse": {
             "type": "object",
-            "required": [
-                "refresh_token"
-            ],
             "properties": {
-                "refresh_token": {
-                    "type": "string"
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.SemanticSearchResult"
+                    }
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "handlers.RegisterInput": {
+        "response.SemanticSearchResult": {
             "type": "object",
-            "required": [
-                "email",
-                "nickname",
-                "password"
-            ],
             "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "user@example.com"
+                "chunk": {
+                    "description": "Фрагмент заметки, лучше всего совпавший с запросом",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/response.ChunkMatch"
+                        }
+                    ]
                 },
-                "nickname": {
-                    "type": "string",
-                    "example": "user123"
+                "note": {
+                    "$ref": "#/definitions/response.NoteResponse"
                 },
-                "password": {
-                    "type": "string",
-                    "example": "yi29jksA"
+                "score": {
+                    "description": "Косинусное сходство с запросом",
+                    "type": "number",
+                    "example": 0.87
                 }
             }
         },
-        "handlers.TagInput": {
+        "response.SuccessResponse": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "description": {
+                "message": {
                     "type": "string"
-                },
-                "name": {
+                }
+            }
+        },
+        "response.SummarizeResponse": {
+            "type": "object",
+            "properties": {
+                "summary": {
                     "type": "string"
                 }
             }
         },
-        "handlers.UpdateProfileInput": {
+        "response.TagCreatedResponse": {
             "type": "object",
             "properties": {
-                "first_name": {
+                "message": {
                     "type": "string"
                 },
-                "last_name": {
-                    "type": "string"
+                "tag": {
+                    "$ref": "#/definitions/response.TagResponse"
+                }
+            }
+        },
+        "response.TagExistsResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "TAG_EXISTS"
                 },
-                "nickname": {
+                "message": {
                     "type": "string"
                 },
-                "profile_pic": {
-                    "type": "string"
+                "tag_id": {
+                    "description": "ID существующего тега",
+                    "type": "integer"
                 }
             }
         },
-        "response.AttachmentShort": {
+        "response.TagResponse": {
             "type": "object",
             "properties": {
-                "file_size": {
-                    "type": "integer"
+                "children": {
+                    "description": "Вложенные теги (в списке с tree=true)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.TagResponse"
+                    }
                 },
-                "file_type": {
+                "color": {
+                    "type": "string",
+                    "example": "#4f46e5"
+                },
+                "description": {
                     "type": "string"
                 },
-                "file_url": {
+                "icon": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
+                },
+                "last_used_at": {
+                    "description": "Когда тег последний раз поставили заметке",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "note_count": {
+                    "description": "Число заметок с тегом (без корзины)",
+                    "type": "integer"
+                },
+                "parent_id": {
+                    "description": "null — тег верхнего уровня",
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "work/clients/acme"
                 }
             }
         },
-        "response.ErrorResponse": {
+        "response.TagShort": {
             "type": "object",
             "properties": {
-                "code": {
+                "color": {
                     "type": "string"
                 },
-                "details": {
+                "icon": {
                     "type": "string"
                 },
-                "message": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "path": {
                     "type": "string"
                 }
             }
         },
-        "response.NoteResponse": {
+        "response.TagSuggestion": {
             "type": "object",
             "properties": {
-                "attachments": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/response.AttachmentShort"
-                    }
+                "confidence": {
+                    "description": "0..1, среднее по источникам из sources",
+                    "type": "number"
                 },
-                "content": {
-                    "type": "string"
+                "embedding_score": {
+                    "description": "Сходство с заметками под этим тегом",
+                    "type": "number"
                 },
-                "created_at": {
-                    "type": "string"
+                "is_new": {
+                    "type": "boolean"
                 },
-                "id": {
-                    "type": "integer"
+                "llm_score": {
+                    "description": "Уверенность языковой модели",
+                    "type": "number"
                 },
-                "is_archived": {
-                    "type": "boolean"
+                "name": {
+                    "type": "string"
                 },
-                "related_ids": {
+                "sources": {
+                    "description": "embedding, llm",
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "type": "string"
                     }
                 },
-                "summary": {
-                    "type": "string"
+                "tag_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.TagSuggestionsResponse": {
+            "type": "object",
+            "properties": {
+                "note_id": {
+                    "type": "integer"
                 },
-                "tags": {
+                "suggestions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/response.TagShort"
+                        "$ref": "#/definitions/response.TagSuggestion"
                     }
                 },
-                "title": {
-                    "type": "string"
-                },
-                "topic_id": {
+                "total": {
                     "type": "integer"
-                },
-                "updated_at": {
-                    "type": "string"
                 }
             }
         },
-        "response.NotesListResponse": {
+        "response.TagsListResponse": {
             "type": "object",
             "properties": {
-                "notes": {
+                "tags": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/response.NoteResponse"
+                        "$ref": "#/definitions/response.TagResponse"
                     }
                 },
                 "total": {
@@ -1076,46 +3803,85 @@ const docTemplate = `{
                 }
             }
         },
-        "response.ProfileResponse": {
+        "response.TextSearchResponse": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string"
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.TextSearchResult"
+                    }
                 },
-                "first_name": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.TextSearchResult": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "$ref": "#/definitions/response.NoteResponse"
                 },
-                "last_name": {
-                    "type": "string"
+                "rank": {
+                    "description": "Ранг ts_rank_cd",
+                    "type": "number",
+                    "example": 0.42
                 },
-                "nickname": {
+                "snippet": {
+                    "description": "Фрагменты текста с подсветкой \u003cmark\u003e, HTML-экранированы",
                     "type": "string"
                 },
-                "profile_pic": {
-                    "description": "Ссылка на фото профиля",
-                    "type": "string"
+                "title_highlight": {
+                    "description": "Заголовок с подсветкой совпадений, HTML-экранирован",
+                    "type": "string",
+                    "example": "Мои \u003cmark\u003eзаметки\u003c/mark\u003e"
                 }
             }
         },
-        "response.SuccessResponse": {
+        "response.TokenResponse": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "access_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJI..."
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOi..."
                 }
             }
         },
-        "response.SummarizeResponse": {
+        "response.TopicResponse": {
             "type": "object",
             "properties": {
-                "summary": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
+                },
+                "parent_id": {
+                    "description": "null — тема верхнего уровня",
+                    "type": "integer"
                 }
             }
         },
-        "response.TagResponse": {
+        "response.TopicTreeNode": {
             "type": "object",
             "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.TopicTreeNode"
+                    }
+                },
                 "description": {
                     "type": "string"
                 },
@@ -1124,27 +3890,53 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "note_count": {
+                    "description": "Заметки непосредственно в теме",
+                    "type": "integer"
+                },
+                "total_note_count": {
+                    "description": "Заметки в теме и во всех вложенных",
+                    "type": "integer"
                 }
             }
         },
-        "response.TagShort": {
+        "response.TopicTreeResponse": {
             "type": "object",
             "properties": {
-                "id": {
+                "topics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.TopicTreeNode"
+                    }
+                },
+                "unsorted_count": {
+                    "description": "Заметки вне тем",
                     "type": "integer"
+                }
+            }
+        },
+        "response.TopicsListResponse": {
+            "type": "object",
+            "properties": {
+                "topics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.TopicResponse"
+                    }
                 },
-                "name": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "response.TagsListResponse": {
+        "response.TrashListResponse": {
             "type": "object",
             "properties": {
-                "tags": {
+                "notes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/response.TagResponse"
+                        "$ref": "#/definitions/response.TrashNoteResponse"
                     }
                 },
                 "total": {
@@ -1152,16 +3944,18 @@ const docTemplate = `{
                 }
             }
         },
-        "response.TokenResponse": {
+        "response.TrashNoteResponse": {
             "type": "object",
             "properties": {
-                "access_token": {
-                    "type": "string",
-                    "example": "eyJhbGciOiJI..."
+                "deleted_at": {
+                    "type": "string"
                 },
-                "refresh_token": {
-                    "type": "string",
-                    "example": "eyJhbGciOi..."
+                "note": {
+                    "$ref": "#/definitions/response.NoteResponse"
+                },
+                "purge_at": {
+                    "description": "Когда заметка будет удалена окончательно",
+                    "type": "string"
                 }
             }
         },
@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"NeuroNest/internal/db"
 	"NeuroNest/internal/handlers"
+	"NeuroNest/internal/models"
 	"NeuroNest/internal/response"
 	"net/http"
 	"strings"
@@ -61,3 +63,20 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AdminMiddleware пропускает только администраторов. Ставится после AuthMiddleware.
+// Роль читается из БД, а не из токена, чтобы снятие роли действовало сразу
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user models.User
+		if err := db.DB.Select("id", "role").Where("id = ?", c.GetUint("userID")).First(&user).Error; err != nil || user.Role != models.RoleAdmin {
+			c.JSON(http.StatusForbidden, response.ErrorResponse{
+				Code:    "FORBIDDEN",
+				Message: "Недостаточно прав",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -3,18 +3,51 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
 
 var (
-	YandexClientID     string
-	YandexClientSecret string
-	YandexRedirectURL  string
-	UploadsPath        string
-	BaseURL            string
-	IAMtoken           string
-	CatalogID          string
+	YandexClientID           string
+	YandexClientSecret       string
+	YandexRedirectURL        string
+	UploadsPath              string
+	BaseURL                  string
+	IAMtoken                 string
+	YandexSAKeyFile          string // Путь к авторизованному ключу сервисного аккаунта; если задан, IAM-токен обновляется автоматически
+	CatalogID                string
+	EmbeddingDim             int     // Размерность эмбеддингов (для колонки pgvector)
+	VectorIndexType          string  // Тип ANN-индекса pgvector: hnsw или ivfflat
+	VectorEfSearch           int     // hnsw.ef_search при поиске: сколько кандидатов просматривает HNSW-индекс
+	VectorProbes             int     // ivfflat.probes при поиске: сколько списков просматривает IVFFlat-индекс
+	TrashRetentionDays       int     // Сколько дней заметка хранится в корзине до окончательного удаления
+	TrashPurgeInterval       int     // Интервал фоновой очистки корзины в минутах
+	HybridMethod             string  // Способ слияния гибридного поиска: rrf или weighted
+	HybridTextWeight         float64 // Вес полнотекстового ранга при weighted (0..1)
+	HybridRRFK               int     // Константа k для reciprocal rank fusion
+	HybridMinSimilarity      float64 // Минимальное косинусное сходство, засчитываемое как семантическое совпадение
+	LLMProvider              string  // Провайдер модели: yandex, openai или fake
+	OpenAIBaseURL            string  // Адрес OpenAI-совместимого API, например http://localhost:11434/v1
+	OpenAIAPIKey             string
+	OpenAIChatModel          string
+	OpenAIEmbeddingModel     string
+	AIWorkerCount            int     // Число воркеров очереди AI-задач; 0 отключает обработку
+	AIJobMaxAttempts         int     // Сколько раз задача запускается, прежде чем перейти в dead
+	AIJobBackoffSeconds      int     // Базовая задержка перед повтором, удваивается с каждой попыткой
+	AIJobPollSeconds         int     // Как часто свободный воркер проверяет очередь
+	AICompleteTimeoutSeconds int     // Таймаут одной попытки генерации текста
+	AIStreamTimeoutSeconds   int     // Таймаут потоковой генерации целиком
+	AIEmbedTimeoutSeconds    int     // Таймаут одной попытки расчёта эмбеддинга
+	AIMaxRetries             int     // Число повторов запроса к модели после 429, 5xx и сетевых ошибок
+	AIBreakerFailures        int     // Число отказов подряд, после которого запросы к модели временно не отправляются; 0 отключает breaker
+	AIBreakerCooldownSeconds int     // Сколько секунд запросы к модели не отправляются после срабатывания breaker
+	EmbeddingCacheSize       int     // Число эмбеддингов в LRU-кэше в памяти; 0 — только кэш в Postgres
+	ChunkSize                int     // Максимальная длина фрагмента заметки для эмбеддинга, в символах
+	ChunkOverlap             int     // Перекрытие соседних фрагментов, в символах
+	RelatedNotesCount        int     // Сколько ближайших по эмбеддингу заметок сохраняется как предложенные связанные
+	RelatedMinSimilarity     float64 // Минимальное косинусное сходство предложенной связанной заметки
+	DuplicateSimilarity      float64 // Косинусное сходство эмбеддингов, начиная с которого заметки считаются почти дубликатами
 )
 
 func LoadEnv() {
@@ -27,5 +60,73 @@ func LoadEnv() {
 	UploadsPath = os.Getenv("UPLOADS_PATH")
 	BaseURL = os.Getenv("BASE_URL")
 	IAMtoken = os.Getenv("IAM_TOKEN")
+	YandexSAKeyFile = os.Getenv("YANDEX_SA_KEY_FILE")
 	CatalogID = os.Getenv("CATALOG_ID")
+	EmbeddingDim = getEnvInt("EMBEDDING_DIM", 256)
+	VectorIndexType = getEnv("VECTOR_INDEX_TYPE", "hnsw")
+	VectorEfSearch = getEnvInt("VECTOR_EF_SEARCH", 200)
+	VectorProbes = getEnvInt("VECTOR_PROBES", 10)
+	TrashRetentionDays = getEnvInt("TRASH_RETENTION_DAYS", 30)
+	TrashPurgeInterval = getEnvInt("TRASH_PURGE_INTERVAL_MINUTES", 60)
+	HybridMethod = getEnv("HYBRID_METHOD", "rrf")
+	HybridTextWeight = getEnvFloat("HYBRID_TEXT_WEIGHT", 0.5)
+	HybridRRFK = getEnvInt("HYBRID_RRF_K", 60)
+	HybridMinSimilarity = getEnvFloat("HYBRID_MIN_SIMILARITY", 0.3)
+	LLMProvider = getEnv("LLM_PROVIDER", "yandex")
+	OpenAIBaseURL = getEnv("OPENAI_BASE_URL", "http://localhost:11434/v1")
+	OpenAIAPIKey = os.Getenv("OPENAI_API_KEY")
+	OpenAIChatModel = os.Getenv("OPENAI_CHAT_MODEL")
+	OpenAIEmbeddingModel = os.Getenv("OPENAI_EMBEDDING_MODEL")
+	AIWorkerCount = getEnvInt("AI_WORKER_COUNT", 2)
+	AIJobMaxAttempts = getEnvInt("AI_JOB_MAX_ATTEMPTS", 5)
+	AIJobBackoffSeconds = getEnvInt("AI_JOB_BACKOFF_SECONDS", 10)
+	AIJobPollSeconds = getEnvInt("AI_JOB_POLL_SECONDS", 2)
+	AICompleteTimeoutSeconds = getEnvInt("AI_COMPLETE_TIMEOUT_SECONDS", 60)
+	AIStreamTimeoutSeconds = getEnvInt("AI_STREAM_TIMEOUT_SECONDS", 180)
+	AIEmbedTimeoutSeconds = getEnvInt("AI_EMBED_TIMEOUT_SECONDS", 15)
+	AIMaxRetries = getEnvInt("AI_MAX_RETRIES", 3)
+	AIBreakerFailures = getEnvInt("AI_BREAKER_FAILURES", 5)
+	AIBreakerCooldownSeconds = getEnvInt("AI_BREAKER_COOLDOWN_SECONDS", 30)
+	EmbeddingCacheSize = getEnvInt("EMBEDDING_CACHE_SIZE", 1000)
+	ChunkSize = getEnvInt("CHUNK_SIZE", 1500)
+	ChunkOverlap = getEnvInt("CHUNK_OVERLAP", 200)
+	RelatedNotesCount = getEnvInt("RELATED_NOTES_COUNT", 5)
+	RelatedMinSimilarity = getEnvFloat("RELATED_MIN_SIMILARITY", 0.5)
+	DuplicateSimilarity = getEnvFloat("DUPLICATE_SIMILARITY", 0.95)
+}
+
+// getEnv возвращает значение переменной окружения или значение по умолчанию
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// getEnvInt возвращает целочисленное значение переменной окружения или значение по умолчанию
+func getEnvInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("Некорректное значение %s=%q, используется %d", key, v, def)
+		return def
+	}
+	return n
+}
+
+// getEnvFloat возвращает дробное значение переменной окружения или значение по умолчанию
+func getEnvFloat(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		log.Printf("Некорректное значение %s=%q, используется %g", key, v, def)
+		return def
+	}
+	return f
 }
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"NeuroNest/internal/models"
+	"encoding/json"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmbeddingCacheStore хранит кэш эмбеддингов в таблице embedding_cache (реализует service.EmbeddingStore)
+type EmbeddingCacheStore struct{}
+
+func (EmbeddingCacheStore) GetEmbedding(textHash, modelURI string) ([]float64, bool, error) {
+	var entry models.EmbeddingCacheEntry
+	err := DB.Where("text_hash = ? AND model_uri = ?", textHash, modelURI).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var embedding []float64
+	if err := json.Unmarshal(entry.Embedding, &embedding); err != nil {
+		return nil, false, err
+	}
+	return embedding, true, nil
+}
+
+func (EmbeddingCacheStore) PutEmbedding(textHash, modelURI string, embedding []float64) error {
+	data, err := json.Marshal(embedding)
+	if err != nil {
+		return err
+	}
+	// Тот же текст мог одновременно посчитать другой запрос: его запись оставляем
+	return DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.EmbeddingCacheEntry{
+		TextHash:  textHash,
+		ModelURI:  modelURI,
+		Embedding: data,
+	}).Error
+}
@@ -0,0 +1,12 @@
+package db
+
+import "errors"
+
+// uniqueViolation — SQLSTATE нарушения уникальности в Postgres
+const uniqueViolation = "23505"
+
+// IsUniqueViolation сообщает, что запрос отклонён уникальным индексом или ограничением
+func IsUniqueViolation(err error) bool {
+	var pgErr interface{ SQLState() string }
+	return errors.As(err, &pgErr) && pgErr.SQLState() == uniqueViolation
+}
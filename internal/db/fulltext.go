@@ -0,0 +1,26 @@
+package db
+
+import "log"
+
+// SetupFullTextSearch добавляет в notes генерируемую колонку search_vector и GIN-индекс по ней.
+// Заголовок, резюме и содержимое индексируются в конфигурациях russian и english с весами A, B и C.
+func SetupFullTextSearch() {
+	stmts := []string{
+		`ALTER TABLE notes ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('russian', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('russian', coalesce(summary, '')), 'B') ||
+			setweight(to_tsvector('english', coalesce(summary, '')), 'B') ||
+			setweight(to_tsvector('russian', coalesce(content, '')), 'C') ||
+			setweight(to_tsvector('english', coalesce(content, '')), 'C')
+		) STORED`,
+		"CREATE INDEX IF NOT EXISTS idx_notes_search_vector ON notes USING gin (search_vector)",
+	}
+	for _, stmt := range stmts {
+		if err := DB.Exec(stmt).Error; err != nil {
+			log.Printf("Ошибка настройки полнотекстового поиска: %v", err)
+			return
+		}
+	}
+	log.Println("Полнотекстовый поиск по заметкам настроен")
+}
@@ -7,11 +7,19 @@ import (
 )
 
 func AutoMigrateTables() {
+	dropGlobalTagNameUnique()
+	prepareTagPaths()
 	if err := DB.AutoMigrate(
 		&models.User{},
 		&models.Note{},
 		&models.Tag{},
+		&models.Topic{},
 		&models.Attachment{},
+		&models.NoteRevision{},
+		&models.AIJob{},
+		&models.EmbeddingCacheEntry{},
+		&models.NoteChunk{},
+		&models.NoteRelation{},
 		&models.ChatHistory{},
 		&models.ActivityLog{},
 		&models.IntegrationLog{},
@@ -19,5 +27,58 @@ func AutoMigrateTables() {
 	); err != nil {
 		log.Fatalf("Ошибка при миграции таблиц: %v", err)
 	}
+	setupNoteTagsTimestamps()
 	log.Println("Автомиграция таблиц завершена успешно")
 }
+
+// dropGlobalTagNameUnique снимает прежнюю уникальность имени тега по всей таблице
+// (в зависимости от версии GORM это ограничение uni_tags_name, tags_name_key или индекс idx_tags_name).
+// Теперь имя уникально в пределах пользователя (idx_tags_user_name); данные не меняются:
+// глобально уникальные имена уникальны и внутри каждого пользователя
+func dropGlobalTagNameUnique() {
+	stmts := []string{
+		"ALTER TABLE IF EXISTS tags DROP CONSTRAINT IF EXISTS uni_tags_name",
+		"ALTER TABLE IF EXISTS tags DROP CONSTRAINT IF EXISTS tags_name_key",
+		"DROP INDEX IF EXISTS idx_tags_name",
+	}
+	for _, stmt := range stmts {
+		if err := DB.Exec(stmt).Error; err != nil {
+			log.Fatalf("Ошибка при снятии уникальности имени тега: %v", err)
+		}
+	}
+}
+
+// prepareTagPaths до автомиграции заполняет путь у существующих тегов их именем, иначе уникальный индекс
+// idx_tags_user_path не построится на пустых путях, и снимает уникальность имени: имена повторяются в разных ветках.
+// Имена со «/» затем раскладывает по иерархии handlers.SplitLegacyTagPaths
+func prepareTagPaths() {
+	if !DB.Migrator().HasTable("tags") {
+		return
+	}
+	stmts := []string{
+		"ALTER TABLE tags ADD COLUMN IF NOT EXISTS path text",
+		"UPDATE tags SET path = name WHERE path IS NULL OR path = ''",
+		"DROP INDEX IF EXISTS idx_tags_user_name",
+	}
+	for _, stmt := range stmts {
+		if err := DB.Exec(stmt).Error; err != nil {
+			log.Fatalf("Ошибка при миграции путей тегов: %v", err)
+		}
+	}
+}
+
+// setupNoteTagsTimestamps добавляет в note_tags время привязки тега к заметке (для «последнего использования» тега).
+// Уже существующим связям проставляется дата создания заметки
+func setupNoteTagsTimestamps() {
+	stmts := []string{
+		"ALTER TABLE note_tags ADD COLUMN IF NOT EXISTS created_at timestamptz",
+		`UPDATE note_tags SET created_at = notes.created_at FROM notes
+			WHERE notes.id = note_tags.note_id AND note_tags.created_at IS NULL`,
+		"ALTER TABLE note_tags ALTER COLUMN created_at SET DEFAULT now()",
+	}
+	for _, stmt := range stmts {
+		if err := DB.Exec(stmt).Error; err != nil {
+			log.Fatalf("Ошибка при миграции note_tags: %v", err)
+		}
+	}
+}
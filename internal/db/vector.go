@@ -0,0 +1,153 @@
+package db
+
+import (
+	"NeuroNest/internal/config"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// VectorEnabled — true, если расширение pgvector установлено и векторные колонки готовы.
+// Иначе поиск по эмбеддингам выполняется перебором в Go по JSON из колонки embedding.
+var VectorEnabled bool
+
+// vectorIterativeScan — pgvector 0.8+: индекс продолжает сканирование, пока фильтр WHERE не наберёт LIMIT строк
+var vectorIterativeScan bool
+
+// vectorTables таблицы, в которых хранится эмбеддинг
+var vectorTables = []string{"notes", "note_chunks", "chat_histories"}
+
+// SetupVectorStorage включает хранение эмбеддингов в pgvector:
+// создаёт колонку embedding_vec, переносит в неё старые JSON-эмбеддинги и строит ANN-индекс.
+// JSON в колонке embedding продолжает записываться, чтобы без расширения поиск работал как раньше.
+func SetupVectorStorage() {
+	if err := DB.Exec("CREATE EXTENSION IF NOT EXISTS vector").Error; err != nil {
+		log.Printf("pgvector недоступен, используется поиск в Go: %v", err)
+		return
+	}
+
+	dim := config.EmbeddingDim
+	for _, table := range vectorTables {
+		if err := dropMismatchedVectorColumn(table, dim); err != nil {
+			log.Printf("Ошибка настройки pgvector для %s, используется поиск в Go: %v", table, err)
+			return
+		}
+		stmts := []string{
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS embedding_vec vector(%d)", table, dim),
+			// Переносим эмбеддинги, сохранённые как JSON-массив: его текст совпадает с форматом литерала vector
+			fmt.Sprintf(`UPDATE %s SET embedding_vec = convert_from(embedding, 'UTF8')::vector
+				WHERE embedding IS NOT NULL AND embedding_vec IS NULL
+				AND jsonb_array_length(convert_from(embedding, 'UTF8')::jsonb) = %d`, table, dim),
+			vectorIndexSQL(table),
+		}
+		for _, stmt := range stmts {
+			if err := DB.Exec(stmt).Error; err != nil {
+				log.Printf("Ошибка настройки pgvector для %s, используется поиск в Go: %v", table, err)
+				return
+			}
+		}
+	}
+
+	var version string
+	if err := DB.Raw("SELECT extversion FROM pg_extension WHERE extname = 'vector'").Scan(&version).Error; err != nil {
+		log.Printf("Ошибка получения версии pgvector: %v", err)
+	}
+	vectorIterativeScan = vectorVersionAtLeast(version, 0, 8)
+
+	VectorEnabled = true
+	log.Printf("Хранение эмбеддингов в pgvector %s включено", version)
+}
+
+// vectorVersionAtLeast сравнивает версию расширения вида 0.8.0 с major.minor
+func vectorVersionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	gotMajor, err1 := strconv.Atoi(parts[0])
+	gotMinor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return gotMajor > major || gotMajor == major && gotMinor >= minor
+}
+
+// VectorSearch выполняет fn в транзакции с настройками ANN-поиска. Фильтр по пользователю применяется
+// уже после сканирования индекса: без них индекс отдаёт около 40 кандидатов со всей таблицы,
+// и у пользователя, чьих векторов среди них нет, результат получается неполным или пустым.
+// Поэтому поднимаются ef_search / probes, а на pgvector 0.8+ включается итеративное сканирование.
+// С ivfflat порядок строк при этом приблизительный: вызывающий досортировывает результат
+func VectorSearch(fn func(tx *gorm.DB) error) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var stmts []string
+		if config.VectorIndexType == "ivfflat" {
+			stmts = append(stmts, fmt.Sprintf("SET LOCAL ivfflat.probes = %d", max(config.VectorProbes, 1)))
+			if vectorIterativeScan {
+				stmts = append(stmts, "SET LOCAL ivfflat.iterative_scan = relaxed_order")
+			}
+		} else {
+			stmts = append(stmts, fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", min(max(config.VectorEfSearch, 1), 1000)))
+			if vectorIterativeScan {
+				stmts = append(stmts, "SET LOCAL hnsw.iterative_scan = strict_order")
+			}
+		}
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return fn(tx)
+	})
+}
+
+// dropMismatchedVectorColumn удаляет колонку embedding_vec, если её размерность не совпадает с EMBEDDING_DIM
+// (сменилась модель эмбеддингов). Колонка создаётся заново, а эмбеддинги пересчитываются командой reindex
+func dropMismatchedVectorColumn(table string, dim int) error {
+	var current []int
+	if err := DB.Raw(`SELECT atttypmod FROM pg_attribute
+		WHERE attrelid = ?::regclass AND attname = 'embedding_vec' AND NOT attisdropped`, table).
+		Scan(&current).Error; err != nil {
+		return err
+	}
+	if len(current) == 0 || current[0] == dim {
+		return nil
+	}
+	log.Printf("Размерность %s.embedding_vec %d не совпадает с EMBEDDING_DIM=%d: колонка будет пересоздана, запустите reindex", table, current[0], dim)
+	return DB.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN embedding_vec", table)).Error
+}
+
+func vectorIndexSQL(table string) string {
+	if config.VectorIndexType == "ivfflat" {
+		return fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_embedding_vec ON %s USING ivfflat (embedding_vec vector_cosine_ops) WITH (lists = 100)", table, table)
+	}
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_embedding_vec ON %s USING hnsw (embedding_vec vector_cosine_ops)", table, table)
+}
+
+// SaveEmbeddingVector записывает эмбеддинг строки в колонку pgvector в рамках tx,
+// чтобы вектор сохранялся вместе с самой строкой. Без pgvector ничего не делает.
+func SaveEmbeddingVector(tx *gorm.DB, table string, id uint, emb []float64) error {
+	if !VectorEnabled || len(emb) == 0 {
+		return nil
+	}
+	if len(emb) != config.EmbeddingDim {
+		return fmt.Errorf("размерность эмбеддинга %d не совпадает с EMBEDDING_DIM=%d", len(emb), config.EmbeddingDim)
+	}
+	return tx.Exec(fmt.Sprintf("UPDATE %s SET embedding_vec = ?::vector WHERE id = ?", table), VectorLiteral(emb), id).Error
+}
+
+// VectorLiteral форматирует вектор в текстовый литерал pgvector: [0.1,0.2,...]
+func VectorLiteral(emb []float64) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, v := range emb {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"NeuroNest/internal/response"
+	"NeuroNest/internal/service"
+	"errors"
+	"net/http"
+)
+
+// aiError превращает ошибку обращения к модели в HTTP-статус и ответ.
+// Недоступность провайдера, превышение лимита и таймаут получают свои коды,
+// остальные ошибки возвращаются как 500 с переданными message и code
+func aiError(err error, message, code string) (int, response.ErrorResponse) {
+	switch {
+	case errors.Is(err, service.ErrAIRateLimited):
+		return http.StatusTooManyRequests, response.ErrorResponse{
+			Message: "Превышен лимит запросов к AI-сервису, попробуйте позже",
+			Code:    "AI_RATE_LIMITED",
+			Details: err.Error(),
+		}
+	case errors.Is(err, service.ErrAITimeout):
+		return http.StatusGatewayTimeout, response.ErrorResponse{
+			Message: "AI-сервис не ответил вовремя",
+			Code:    "AI_TIMEOUT",
+			Details: err.Error(),
+		}
+	case errors.Is(err, service.ErrAIUnavailable), errors.Is(err, service.ErrProviderNotConfigured):
+		return http.StatusServiceUnavailable, response.ErrorResponse{
+			Message: "AI-сервис временно недоступен",
+			Code:    "AI_UNAVAILABLE",
+			Details: err.Error(),
+		}
+	}
+	return http.StatusInternalServerError, response.ErrorResponse{
+		Message: message,
+		Code:    code,
+		Details: err.Error(),
+	}
+}
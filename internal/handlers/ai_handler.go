@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/response"
+	"NeuroNest/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetEmbeddingCacheStatsHandler godoc
+// @Security		BearerAuth
+// @Summary		Статистика кэша эмбеддингов
+// @Description	Возвращает число попаданий и промахов кэша эмбеддингов с момента запуска сервера и размер кэша.
+// @Description	Статистика общая для всех пользователей, поэтому доступна только администраторам
+// @Tags			ai
+// @Produce		json
+// @Success		200	{object}	response.EmbeddingCacheStatsResponse	"Статистика кэша"
+// @Failure		403	{object}	response.ErrorResponse	"Пользователь не администратор FORBIDDEN"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка БД DB_ERROR"
+// @Router			/ai/embedding-cache/stats [get]
+func GetEmbeddingCacheStatsHandler(c *gin.Context) {
+	stats, enabled := service.GetEmbeddingCacheStats()
+
+	var stored int64
+	if err := db.DB.Model(&models.EmbeddingCacheEntry{}).Count(&stored).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении статистики кэша",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	hits := stats.MemoryHits + stats.StoreHits
+	var hitRate float64
+	if total := hits + stats.Misses; total > 0 {
+		hitRate = float64(hits) / float64(total)
+	}
+
+	c.JSON(http.StatusOK, response.EmbeddingCacheStatsResponse{
+		Enabled:       enabled,
+		Hits:          hits,
+		MemoryHits:    stats.MemoryHits,
+		StoreHits:     stats.StoreHits,
+		Misses:        stats.Misses,
+		HitRate:       hitRate,
+		MemoryEntries: stats.Entries,
+		StoredEntries: stored,
+	})
+}
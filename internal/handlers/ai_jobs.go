@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"NeuroNest/internal/config"
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/service"
+	"NeuroNest/internal/textchunk"
+	"NeuroNest/internal/worker"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// RegisterAIJobs регистрирует обработчики фоновых AI-задач по заметкам
+func RegisterAIJobs() {
+	worker.Register(models.AIJobEmbed, embedNoteJob)
+	worker.Register(models.AIJobSummarize, summarizeNoteJob)
+	worker.Register(models.AIJobAutoTag, autoTagNoteJob)
+}
+
+// loadJobNote загружает заметку задачи. Удалённая заметка не считается ошибкой: задача просто завершается
+func loadJobNote(job models.AIJob) (*models.Note, error) {
+	var note models.Note
+	err := db.DB.Where("id = ? AND user_id = ?", job.NoteID, job.UserID).First(&note).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// embedNoteJob пересчитывает эмбеддинги заметки по её текущему содержимому
+// и после первого расчёта ставит в очередь автоматическое проставление тегов
+func embedNoteJob(ctx context.Context, job models.AIJob) error {
+	note, err := loadJobNote(job)
+	if err != nil || note == nil {
+		return err
+	}
+	if err := EmbedNote(*note); err != nil {
+		return err
+	}
+	if err := enqueueAutoTag(*note); err != nil {
+		log.Printf("Ошибка постановки заметки %d в очередь автотегов: %v", note.ID, err)
+	}
+	return nil
+}
+
+// EmbedNote делит содержимое заметки на фрагменты, считает эмбеддинг каждого моделью для документов
+// и сохраняет их вместе с эмбеддингом заметки целиком (нормированным средним фрагментов) и URI модели,
+// после чего пересчитывает предложенные связанные заметки и дубликаты. Используется фоновой задачей и командой переиндексации
+func EmbedNote(note models.Note) error {
+	model, err := service.EmbeddingModel(service.EmbeddingDocument)
+	if err != nil {
+		return err
+	}
+
+	pieces := textchunk.Split(note.Content, config.ChunkSize, config.ChunkOverlap)
+	chunks := make([]models.NoteChunk, 0, len(pieces))
+	vectors := make([][]float64, 0, len(pieces))
+	for _, piece := range pieces {
+		embedding, embBytes, err := embedContent(piece.Text)
+		if err != nil {
+			return err
+		}
+		vectors = append(vectors, embedding)
+		chunks = append(chunks, models.NoteChunk{
+			NoteID:         note.ID,
+			UserID:         note.UserID,
+			ChunkIndex:     piece.Index,
+			StartOffset:    piece.Start,
+			EndOffset:      piece.End,
+			Text:           piece.Text,
+			Embedding:      embBytes,
+			EmbeddingModel: model,
+			EmbeddingDim:   len(embedding),
+		})
+	}
+
+	if len(vectors) == 0 {
+		return nil
+	}
+	noteEmbedding := service.MeanEmbedding(vectors)
+	noteEmbBytes, err := json.Marshal(noteEmbedding)
+	if err != nil {
+		return err
+	}
+
+	saved := false
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		// Содержимое могло измениться, пока считались эмбеддинги: тогда в очереди уже есть новая задача,
+		// а этот результат записывать нельзя
+		res := tx.Model(&models.Note{}).
+			Where("id = ? AND content = ?", note.ID, note.Content).
+			UpdateColumns(map[string]interface{}{
+				"embedding":       noteEmbBytes,
+				"embedding_model": model,
+				"embedding_dim":   len(noteEmbedding),
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		if err := tx.Where("note_id = ?", note.ID).Delete(&models.NoteChunk{}).Error; err != nil {
+			return err
+		}
+		if len(chunks) > 0 {
+			if err := tx.Create(&chunks).Error; err != nil {
+				return err
+			}
+		}
+		// Векторы pgvector пишутся в той же транзакции: иначе до их записи заметка и её фрагменты
+		// остались бы с пустыми векторами и выпали бы из семантического поиска
+		if err := db.SaveEmbeddingVector(tx, "notes", note.ID, noteEmbedding); err != nil {
+			return err
+		}
+		for i, chunk := range chunks {
+			if err := db.SaveEmbeddingVector(tx, "note_chunks", chunk.ID, vectors[i]); err != nil {
+				return err
+			}
+		}
+		saved = true
+		return nil
+	})
+	if err != nil || !saved {
+		return err
+	}
+
+	if err := refreshNoteRelations(note, noteEmbedding, model); err != nil {
+		return err
+	}
+	return recordNoteDuplicates(note, noteEmbedding, model)
+}
+
+// EmbedChatHistory пересчитывает эмбеддинг вопроса из истории чата моделью для запросов
+func EmbedChatHistory(entry models.ChatHistory) error {
+	model, err := service.EmbeddingModel(service.EmbeddingQuery)
+	if err != nil {
+		return err
+	}
+	embedding, err := service.GenerateQueryEmbedding(entry.Message)
+	if err != nil {
+		return err
+	}
+	embBytes, err := json.Marshal(embedding)
+	if err != nil {
+		return err
+	}
+
+	if err := db.DB.Model(&entry).UpdateColumns(map[string]interface{}{
+		"embedding":       embBytes,
+		"embedding_model": model,
+		"embedding_dim":   len(embedding),
+	}).Error; err != nil {
+		return err
+	}
+	return db.SaveEmbeddingVector(db.DB, "chat_histories", entry.ID, embedding)
+}
+
+// EnqueueUnchunkedNotes ставит в очередь пересчёт эмбеддингов для заметок, у которых ещё нет фрагментов
+// (созданных до появления фрагментов), чтобы они находились семантическим поиском.
+// Пропускаются заметки из одних пробелов (у них фрагментов не бывает) и заметки, последняя задача
+// эмбеддинга которых ещё в очереди или исчерпала попытки, — иначе они ставились бы заново при каждом запуске
+func EnqueueUnchunkedNotes() {
+	var notes []models.Note
+	err := db.DB.Select("id", "user_id").
+		Where("NOT EXISTS (SELECT 1 FROM note_chunks WHERE note_chunks.note_id = notes.id)").
+		Where(`content ~ '\S'`).
+		Where(`COALESCE((SELECT ai_jobs.status FROM ai_jobs
+			WHERE ai_jobs.note_id = notes.id AND ai_jobs.type = ? AND ai_jobs.deleted_at IS NULL
+			ORDER BY ai_jobs.id DESC LIMIT 1), '') NOT IN ?`,
+			models.AIJobEmbed, []string{models.AIJobPending, models.AIJobRunning, models.AIJobDead}).
+		Find(&notes).Error
+	if err != nil {
+		log.Printf("Ошибка поиска заметок без фрагментов: %v", err)
+		return
+	}
+
+	for _, note := range notes {
+		if err := worker.Enqueue(db.DB, models.AIJobEmbed, note.ID, note.UserID); err != nil {
+			log.Printf("Ошибка постановки заметки %d в очередь эмбеддингов: %v", note.ID, err)
+			return
+		}
+	}
+	if len(notes) > 0 {
+		log.Printf("Поставлено в очередь эмбеддингов заметок без фрагментов: %d", len(notes))
+	}
+}
+
+// summarizeNoteJob генерирует и сохраняет резюме заметки. Если заметку отредактировали во время генерации,
+// резюме не сохраняется, а задача ставится заново — по новому содержимому
+func summarizeNoteJob(ctx context.Context, job models.AIJob) error {
+	note, err := loadJobNote(job)
+	if err != nil || note == nil {
+		return err
+	}
+
+	summary, err := service.SummarizeText(note.Content)
+	if err != nil {
+		return err
+	}
+	err = saveNoteSummary(note, summary)
+	if errors.Is(err, errNoteChanged) {
+		return worker.Enqueue(db.DB, models.AIJobSummarize, note.ID, note.UserID)
+	}
+	return err
+}
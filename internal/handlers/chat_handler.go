@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/response"
+	"NeuroNest/internal/search"
+	"NeuroNest/internal/service"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// chatHistoryTurns сколько предыдущих обменов диалога передаётся модели
+const chatHistoryTurns = 5
+
+// ChatInput вопрос к заметкам пользователя
+type ChatInput struct {
+	Question        string `json:"question" binding:"required" example:"Что я писал про суммаризацию?"`
+	ConversationID  string `json:"conversation_id,omitempty"` // Продолжение существующего диалога
+	TopK            int    `json:"top_k,omitempty" binding:"omitempty,min=1,max=10"`
+	IncludeArchived bool   `json:"include_archived,omitempty"`
+}
+
+// ChatHandler godoc
+// @Security		BearerAuth
+// @Summary		Вопрос к заметкам (RAG)
+// @Description	Находит наиболее релевантные заметки по эмбеддингу вопроса, передаёт их YandexGPT как контекст и возвращает ответ со ссылками на заметки
+// @Tags			chat
+// @Accept			json
+// @Produce		json,text/event-stream
+// @Param			chat	body		ChatInput	true	"Вопрос"
+// @Success		200		{object}	response.ChatResponse	"Ответ модели. При Accept: text/event-stream — события delta с фрагментами и done с результатом"
+// @Failure		400		{object}	response.ErrorResponse	"Ошибка валидации VALIDATION_ERROR"
+// @Failure		500		{object}	response.ErrorResponse	"Ошибка генерации эмбеддинга EMBEDDING_ERROR, ошибка генерации ответа CHAT_ERROR, ошибка БД DB_ERROR"
+// @Failure		429		{object}	response.ErrorResponse	"Превышен лимит запросов к модели AI_RATE_LIMITED"
+// @Failure		503		{object}	response.ErrorResponse	"Модель недоступна AI_UNAVAILABLE"
+// @Failure		504		{object}	response.ErrorResponse	"Модель не ответила вовремя AI_TIMEOUT"
+// @Router			/chat [post]
+func ChatHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input ChatInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	if input.TopK == 0 {
+		input.TopK = 5
+	}
+	if input.ConversationID == "" {
+		input.ConversationID = uuid.New().String()
+	}
+
+	// 1) Эмбеддинг вопроса
+	questionEmb, err := service.GenerateQueryEmbedding(input.Question)
+	if err != nil {
+		c.JSON(aiError(err, "Ошибка генерации эмбеддинга", "EMBEDDING_ERROR"))
+		return
+	}
+
+	// 2) Контекст: самые близкие заметки и предыдущие обмены диалога
+	contextNotes, err := retrieveContextNotes(userID, questionEmb, input.TopK, input.IncludeArchived)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при поиске заметок",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	history, err := loadChatTurns(userID, input.ConversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении истории диалога",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// 3) Ответ модели
+	if wantsEventStream(c) {
+		chatStream(c, userID, input, questionEmb, contextNotes, history)
+		return
+	}
+	answer, cited, err := service.AnswerQuestion(input.Question, contextNotes, history)
+	if err != nil {
+		c.JSON(aiError(err, "Ошибка генерации ответа", "CHAT_ERROR"))
+		return
+	}
+
+	// 4) Сохраняем обмен в историю
+	entry, err := saveChatExchange(userID, input.ConversationID, input.Question, answer, questionEmb, cited)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при сохранении истории чата",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, chatToResponse(entry, contextNotes))
+}
+
+// GetChatHistoryHandler godoc
+// @Security		BearerAuth
+// @Summary		Список диалогов
+// @Description	Возвращает диалоги пользователя с заметками, от последних к ранним
+// @Tags			chat
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	response.ChatConversationsListResponse	"Список диалогов"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка при получении истории DB_ERROR"
+// @Router			/chat/history [get]
+func GetChatHistoryHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var rows []struct {
+		ConversationID string
+		Title          string
+		MessagesCount  int
+		LastAt         time.Time
+	}
+	// Заголовок диалога — его первый вопрос
+	if err := db.DB.Model(&models.ChatHistory{}).
+		Select("conversation_id, (array_agg(message ORDER BY timestamp))[1] AS title, COUNT(*) AS messages_count, MAX(timestamp) AS last_at").
+		Where("user_id = ?", userID).
+		Group("conversation_id").
+		Order("last_at DESC").
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении истории",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	conversations := make([]response.ChatConversationResponse, 0, len(rows))
+	for _, row := range rows {
+		conversations = append(conversations, response.ChatConversationResponse{
+			ConversationID: row.ConversationID,
+			Title:          row.Title,
+			MessagesCount:  row.MessagesCount,
+			LastMessageAt:  row.LastAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	c.JSON(http.StatusOK, response.ChatConversationsListResponse{
+		Conversations: conversations,
+		Total:         len(conversations),
+	})
+}
+
+// GetChatConversationHandler godoc
+// @Security		BearerAuth
+// @Summary		Сообщения диалога
+// @Description	Возвращает все обмены диалога в хронологическом порядке
+// @Tags			chat
+// @Accept			json
+// @Produce		json
+// @Param			conversationId	path		string	true	"ID диалога"
+// @Success		200	{object}	response.ChatMessagesListResponse	"Сообщения диалога"
+// @Failure		404	{object}	response.ErrorResponse	"Диалог не найден CONVERSATION_NOT_FOUND"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка при получении истории DB_ERROR"
+// @Router			/chat/history/{conversationId} [get]
+func GetChatConversationHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+	conversationID := c.Param("conversationId")
+
+	var entries []models.ChatHistory
+	if err := db.DB.Where("user_id = ? AND conversation_id = ?", userID, conversationID).Order("timestamp ASC").Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении истории",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	if len(entries) == 0 {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Диалог не найден",
+			Code:    "CONVERSATION_NOT_FOUND",
+		})
+		return
+	}
+
+	// Заголовки процитированных заметок (удалённые заметки просто пропускаются)
+	var noteIDs []int64
+	for _, entry := range entries {
+		noteIDs = append(noteIDs, entry.NoteIDs...)
+	}
+	var notes []models.Note
+	if len(noteIDs) > 0 {
+		db.DB.Select("id", "title").Where("user_id = ? AND id IN ?", userID, []int64(noteIDs)).Find(&notes)
+	}
+	contextNotes := make([]service.ContextNote, 0, len(notes))
+	for _, note := range notes {
+		contextNotes = append(contextNotes, service.ContextNote{ID: note.ID, Title: note.Title})
+	}
+
+	messages := make([]response.ChatResponse, 0, len(entries))
+	for _, entry := range entries {
+		messages = append(messages, chatToResponse(entry, contextNotes))
+	}
+
+	c.JSON(http.StatusOK, response.ChatMessagesListResponse{
+		ConversationID: conversationID,
+		Messages:       messages,
+		Total:          len(messages),
+	})
+}
+
+// retrieveContextNotes возвращает topK заметок, ближайших к вопросу по эмбеддингу
+func retrieveContextNotes(userID uint, questionEmb []float64, topK int, includeArchived bool) ([]service.ContextNote, error) {
+	hits, err := search.Semantic(userID, questionEmb, search.SemanticOptions{
+		Limit:           topK,
+		IncludeArchived: includeArchived,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.NoteID)
+	}
+	notesByID, err := loadNotesByIDs(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	contextNotes := make([]service.ContextNote, 0, len(hits))
+	for _, hit := range hits {
+		note, ok := notesByID[hit.NoteID]
+		if !ok {
+			continue
+		}
+		contextNotes = append(contextNotes, service.ContextNote{
+			ID:    note.ID,
+			Title: note.Title,
+			Text:  note.Content,
+		})
+	}
+	return contextNotes, nil
+}
+
+// loadChatTurns возвращает последние обмены диалога в хронологическом порядке
+func loadChatTurns(userID uint, conversationID string) ([]service.ChatTurn, error) {
+	var entries []models.ChatHistory
+	if err := db.DB.Where("user_id = ? AND conversation_id = ?", userID, conversationID).
+		Order("timestamp DESC").Limit(chatHistoryTurns).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	turns := make([]service.ChatTurn, len(entries))
+	for i, entry := range entries {
+		turns[len(entries)-1-i] = service.ChatTurn{Question: entry.Message, Answer: entry.Response}
+	}
+	return turns, nil
+}
+
+// saveChatExchange сохраняет вопрос, ответ, эмбеддинг вопроса и процитированные заметки
+func saveChatExchange(userID uint, conversationID, question, answer string, questionEmb []float64, cited []uint) (models.ChatHistory, error) {
+	embBytes, err := json.Marshal(questionEmb)
+	if err != nil {
+		return models.ChatHistory{}, err
+	}
+
+	noteIDs := make(pq.Int64Array, 0, len(cited))
+	for _, id := range cited {
+		noteIDs = append(noteIDs, int64(id))
+	}
+
+	// Ошибка здесь невозможна: эмбеддинг вопроса уже построен текущим провайдером
+	model, _ := service.EmbeddingModel(service.EmbeddingQuery)
+
+	entry := models.ChatHistory{
+		UserID:         userID,
+		ConversationID: conversationID,
+		Message:        question,
+		Response:       answer,
+		Timestamp:      time.Now(),
+		Embedding:      embBytes,
+		EmbeddingModel: model,
+		EmbeddingDim:   len(questionEmb),
+		NoteIDs:        noteIDs,
+	}
+	if err := db.DB.Create(&entry).Error; err != nil {
+		return entry, err
+	}
+
+	if err := db.SaveEmbeddingVector(db.DB, "chat_histories", entry.ID, questionEmb); err != nil {
+		fmt.Printf("embedding vector save error: %v\n", err)
+	}
+	return entry, nil
+}
+
+// chatToResponse преобразует запись истории в ответ API; notes — известные заголовки заметок
+func chatToResponse(entry models.ChatHistory, notes []service.ContextNote) response.ChatResponse {
+	titles := make(map[uint]string, len(notes))
+	for _, note := range notes {
+		titles[note.ID] = note.Title
+	}
+
+	citations := make([]response.ChatCitation, 0, len(entry.NoteIDs))
+	for _, id := range entry.NoteIDs {
+		title, ok := titles[uint(id)]
+		if !ok {
+			continue
+		}
+		citations = append(citations, response.ChatCitation{NoteID: uint(id), Title: title})
+	}
+
+	return response.ChatResponse{
+		ConversationID: entry.ConversationID,
+		Question:       entry.Message,
+		Answer:         entry.Response,
+		Citations:      citations,
+		CreatedAt:      entry.Timestamp.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,384 @@
+package handlers
+
+import (
+	"NeuroNest/internal/config"
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/response"
+	"NeuroNest/internal/search"
+	"NeuroNest/internal/service"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// contentHash — хэш содержимого заметки для поиска точных дубликатов.
+// Нормализация та же, что у ключа кэша эмбеддингов: различия только в пробелах не учитываются
+func contentHash(content string) string {
+	return service.HashEmbeddingText(service.NormalizeEmbeddingText(content))
+}
+
+// duplicateCheckLimit сколько ближайших заметок проверяется на почти дубликат после расчёта эмбеддинга
+const duplicateCheckLimit = 5
+
+// findExactDuplicates ищет неархивные заметки пользователя с тем же содержимым (по хэшу).
+// Модель не вызывается, поэтому проверка выполняется прямо при создании заметки
+func findExactDuplicates(note models.Note) ([]uint, error) {
+	var ids []uint
+	err := db.DB.Model(&models.Note{}).
+		Where("user_id = ? AND id <> ? AND is_archived = ? AND content_hash = ?", note.UserID, note.ID, false, note.ContentHash).
+		Order("id").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// recordNoteDuplicates сохраняет в duplicate_ids заметки её точные и почти дубликаты:
+// заметки с тем же хэшем и ближайшие соседи со сходством эмбеддингов не меньше DuplicateSimilarity.
+// Вызывается фоновой задачей эмбеддингов, когда эмбеддинг заметки уже посчитан
+func recordNoteDuplicates(note models.Note, emb []float64, model string) error {
+	ids, err := findExactDuplicates(note)
+	if err != nil {
+		return err
+	}
+	hits, err := search.Neighbours(note.UserID, note.ID, emb, model, duplicateCheckLimit)
+	if err != nil {
+		return err
+	}
+
+	duplicateIDs := pq.Int64Array{}
+	seen := make(map[uint]bool, len(ids)+len(hits))
+	for _, id := range ids {
+		seen[id] = true
+		duplicateIDs = append(duplicateIDs, int64(id))
+	}
+	for _, hit := range hits {
+		if hit.Score >= config.DuplicateSimilarity && !seen[hit.NoteID] {
+			seen[hit.NoteID] = true
+			duplicateIDs = append(duplicateIDs, int64(hit.NoteID))
+		}
+	}
+	return db.DB.Model(&models.Note{}).Where("id = ?", note.ID).UpdateColumn("duplicate_ids", duplicateIDs).Error
+}
+
+// BackfillContentHashes заполняет хэш содержимого у заметок, созданных до появления поиска дубликатов
+func BackfillContentHashes() {
+	const batch = 500
+	total := 0
+	for {
+		var notes []models.Note
+		if err := db.DB.Unscoped().Select("id", "content").
+			Where("content_hash = '' OR content_hash IS NULL").
+			Order("id").Limit(batch).
+			Find(&notes).Error; err != nil {
+			log.Printf("Ошибка заполнения хэшей содержимого заметок: %v", err)
+			return
+		}
+		for _, note := range notes {
+			if err := db.DB.Unscoped().Model(&models.Note{}).Where("id = ?", note.ID).
+				UpdateColumn("content_hash", contentHash(note.Content)).Error; err != nil {
+				log.Printf("Ошибка заполнения хэша содержимого заметки %d: %v", note.ID, err)
+				return
+			}
+		}
+		total += len(notes)
+		if len(notes) < batch {
+			break
+		}
+	}
+	if total > 0 {
+		log.Printf("Заполнены хэши содержимого заметок: %d", total)
+	}
+}
+
+// GetDuplicatesHandler godoc
+// @Security		BearerAuth
+// @Summary		Дубликаты заметок
+// @Description	Группирует неархивные заметки пользователя в кластеры дубликатов: с одинаковым содержимым (с точностью до пробелов) или со сходством эмбеддингов не ниже DUPLICATE_SIMILARITY. Заметки связываются в кластер транзитивно
+// @Tags			note
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	response.DuplicatesResponse	"Кластеры дубликатов"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка при поиске дубликатов DB_ERROR"
+// @Router			/notes/duplicates [get]
+func GetDuplicatesHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	clusters, err := duplicateClusters(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при поиске дубликатов",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DuplicatesResponse{
+		Clusters: clusters,
+		Total:    len(clusters),
+	})
+}
+
+// duplicateClusters объединяет точные совпадения по хэшу и пары похожих эмбеддингов в компоненты связности
+func duplicateClusters(userID uint) ([]response.DuplicateCluster, error) {
+	// 1) Точные дубликаты: заметки с повторяющимся хэшем содержимого
+	var exact []struct {
+		ID          uint
+		ContentHash string
+	}
+	if err := db.DB.Model(&models.Note{}).
+		Select("id, content_hash").
+		Where("user_id = ? AND is_archived = ? AND content_hash <> ''", userID, false).
+		Where("content_hash IN (?)", db.DB.Model(&models.Note{}).
+			Select("content_hash").
+			Where("user_id = ? AND is_archived = ?", userID, false).
+			Group("content_hash").
+			Having("COUNT(*) > 1")).
+		Order("id").
+		Scan(&exact).Error; err != nil {
+		return nil, err
+	}
+
+	// 2) Почти дубликаты по эмбеддингам текущей модели
+	var pairs []search.Pair
+	if model, err := service.EmbeddingModel(service.EmbeddingDocument); err == nil {
+		if pairs, err = search.SimilarPairs(userID, model, config.DuplicateSimilarity); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3) Компоненты связности (union-find); у точных совпадений сходство 1
+	parent := map[uint]uint{}
+	var find func(uint) uint
+	find = func(x uint) uint {
+		if _, ok := parent[x]; !ok {
+			parent[x] = x
+		}
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	hashes := map[uint]string{}
+	firstByHash := map[string]uint{}
+	for _, row := range exact {
+		hashes[row.ID] = row.ContentHash
+		if first, ok := firstByHash[row.ContentHash]; ok {
+			parent[find(row.ID)] = find(first)
+		} else {
+			firstByHash[row.ContentHash] = row.ID
+			find(row.ID)
+		}
+	}
+	for _, p := range pairs {
+		parent[find(p.NoteID)] = find(p.OtherID)
+	}
+
+	minScore := map[uint]float64{}
+	for _, p := range pairs {
+		root := find(p.NoteID)
+		if s, ok := minScore[root]; !ok || p.Score < s {
+			minScore[root] = p.Score
+		}
+	}
+	members := map[uint][]uint{}
+	ids := make([]uint, 0, len(parent))
+	for id := range parent {
+		root := find(id)
+		members[root] = append(members[root], id)
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return []response.DuplicateCluster{}, nil
+	}
+
+	// 4) Заголовки и даты заметок
+	var notes []models.Note
+	if err := db.DB.Select("id", "title", "content_hash", "updated_at").Where("id IN ?", ids).Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Note, len(notes))
+	for _, n := range notes {
+		byID[n.ID] = n
+	}
+
+	clusters := make([]response.DuplicateCluster, 0, len(members))
+	for root, group := range members {
+		sort.Slice(group, func(i, j int) bool { return group[i] < group[j] })
+		cluster := response.DuplicateCluster{Exact: true, Similarity: 1}
+		if s, ok := minScore[root]; ok {
+			cluster.Similarity = s
+		}
+		for _, id := range group {
+			n, ok := byID[id]
+			if !ok {
+				continue
+			}
+			if n.ContentHash != byID[group[0]].ContentHash || n.ContentHash == "" {
+				cluster.Exact = false
+			}
+			cluster.Notes = append(cluster.Notes, response.DuplicateNote{
+				ID:        n.ID,
+				Title:     n.Title,
+				UpdatedAt: n.UpdatedAt.Format("2006-01-02 15:04:05"),
+			})
+		}
+		if cluster.Exact {
+			cluster.Similarity = 1
+		}
+		if len(cluster.Notes) > 1 {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if len(clusters[i].Notes) != len(clusters[j].Notes) {
+			return len(clusters[i].Notes) > len(clusters[j].Notes)
+		}
+		return clusters[i].Notes[0].ID < clusters[j].Notes[0].ID
+	})
+	return clusters, nil
+}
+
+// MergeNotesInput заметка, вливаемая в заметку из пути
+type MergeNotesInput struct {
+	SourceID      uint `json:"source_id" binding:"required"`
+	AppendContent bool `json:"append_content"` // Дописать содержимое source в конец; иначе остаётся содержимое целевой заметки
+}
+
+// MergeNotesHandler godoc
+// @Security		BearerAuth
+// @Summary		Слияние заметок
+// @Description	Вливает заметку source_id в заметку из пути: объединяет теги и связанные заметки, переносит все вложения, по желанию дописывает содержимое. Ссылки других заметок на source заменяются ссылками на целевую заметку, source перемещается в корзину
+// @Tags			note
+// @Accept			json
+// @Produce		json
+// @Param			id		path		uint			true	"ID целевой заметки"
+// @Param			input	body		MergeNotesInput	true	"Вливаемая заметка"
+// @Success		200		{object}	response.NoteResponse	"Целевая заметка после слияния"
+// @Failure		400		{object}	response.ErrorResponse	"Ошибка валидации VALIDATION_ERROR"
+// @Failure		404		{object}	response.ErrorResponse	"Заметка не найдена NOTE_NOT_FOUND"
+// @Failure		500		{object}	response.ErrorResponse	"Ошибка БД DB_ERROR"
+// @Router			/notes/{id}/merge [post]
+func MergeNotesHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+	noteID := c.Param("id")
+
+	var input MergeNotesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var target, source models.Note
+	if err := db.DB.Where("id = ? AND user_id = ?", noteID, userID).First(&target).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Заметка не найдена",
+			Code:    "NOTE_NOT_FOUND",
+		})
+		return
+	}
+	if target.ID == input.SourceID {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Нельзя слить заметку саму с собой",
+			Code:    "VALIDATION_ERROR",
+		})
+		return
+	}
+	if err := db.DB.Where("id = ? AND user_id = ?", input.SourceID, userID).First(&source).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Заметка не найдена",
+			Code:    "NOTE_NOT_FOUND",
+			Details: "source_id",
+		})
+		return
+	}
+
+	// Связанные заметки обеих, без ссылок друг на друга
+	related := pq.Int64Array{}
+	seen := map[int64]bool{int64(target.ID): true, int64(source.ID): true}
+	for _, id := range append(append([]int64{}, target.RelatedIDs...), source.RelatedIDs...) {
+		if !seen[id] {
+			seen[id] = true
+			related = append(related, id)
+		}
+	}
+	updates := map[string]interface{}{"related_ids": related}
+	target.RelatedIDs = related
+
+	contentChanged := input.AppendContent && source.Content != ""
+	if contentChanged {
+		target.Content += "\n\n" + source.Content
+		target.ContentHash = contentHash(target.Content)
+		updates["content"] = target.Content
+		updates["content_hash"] = target.ContentHash
+		if target.Summary != "" {
+			target.SummaryStale = true
+			updates["summary_stale"] = true
+		}
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		// 1) Теги обеих заметок
+		if err := tx.Exec(`INSERT INTO note_tags (note_id, tag_id)
+			SELECT ?, tag_id FROM note_tags WHERE note_id = ?
+			ON CONFLICT DO NOTHING`, target.ID, source.ID).Error; err != nil {
+			return err
+		}
+		// 2) Все вложения переходят к целевой заметке
+		if err := tx.Model(&models.Attachment{}).Where("note_id = ?", source.ID).Update("note_id", target.ID).Error; err != nil {
+			return err
+		}
+		// 3) Содержимое и связанные заметки
+		if err := tx.Model(&target).Updates(updates).Error; err != nil {
+			return err
+		}
+		// 4) Ссылки других заметок на source ведут на целевую заметку
+		if err := tx.Exec(`UPDATE notes SET related_ids = array_remove(related_ids, ?) ||
+				CASE WHEN ? = ANY(related_ids) OR id = ? THEN '{}'::integer[] ELSE ARRAY[?]::integer[] END
+			WHERE user_id = ? AND ? = ANY(related_ids)`,
+			source.ID, target.ID, target.ID, target.ID, userID, source.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("note_id = ? OR related_id = ?", source.ID, source.ID).Delete(&models.NoteRelation{}).Error; err != nil {
+			return err
+		}
+		// 5) source — в корзину, у целевой заметки — новая ревизия
+		if err := tx.Delete(&source).Error; err != nil {
+			return err
+		}
+		if err := saveRevision(tx, target, models.RevisionActionMerged); err != nil {
+			return err
+		}
+		if contentChanged {
+			return enqueueNoteAIJobs(tx, target, models.AIJobEmbed)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при слиянии заметок",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := db.DB.Where("id = ?", target.ID).Preload("Tags").Preload("Attachments").First(&target).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении заметки",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, noteToResponse(target))
+}
@@ -6,8 +6,11 @@ import (
 	"NeuroNest/internal/models"
 	"NeuroNest/internal/response"
 	"NeuroNest/internal/service"
+	"NeuroNest/internal/worker"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -17,6 +20,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CreateNoteInput структура для создания заметки (multipart/form-data)
@@ -25,12 +30,13 @@ type CreateNoteInput struct {
 	Content    string  `form:"content" binding:"required"`
 	RelatedIDs []int64 `form:"related_ids[]"` // optional, IDs связанных заметок
 	TagIDs     []uint  `form:"tag_ids[]"`     // optional, IDs тегов
+	TopicID    uint    `form:"topic_id"`      // optional, ID темы
 }
 
 // CreateNoteHandler godoc
 // @Security		BearerAuth
 // @Summary		Создать заметку
-// @Description	Создаёт новую заметку пользователя с генерацией эмбеддинга, тегами и вложениями
+// @Description	Создаёт новую заметку пользователя с тегами и вложениями. Эмбеддинг и резюме ставятся в очередь и считаются в фоне. Если у пользователя уже есть заметки с тем же или почти тем же содержимым, их ID возвращаются в duplicate_ids
 // @Tags			note
 // @Accept			multipart/form-data
 // @Produce		json
@@ -38,9 +44,11 @@ type CreateNoteInput struct {
 // @Param			content			formData	string	true	"Содержимое"
 // @Param			related_ids		formData	[]int	false	"ID связанных заметок"
 // @Param			tag_ids			formData	[]int	false	"ID тегов"
+// @Param			topic_id		formData	int		false	"ID темы"
 // @Param			attachments	formData	[]file	false	"Вложения (image, audio, pdf)"
-// @Success		201	{object}	response.SuccessResponse	"Заметка успешно создана"
+// @Success		201	{object}	response.NoteCreatedResponse	"Заметка успешно создана"
 // @Failure		400	{object}	response.ErrorResponse	"Ошибка валидации"
+// @Failure		422	{object}	response.InvalidReferencesResponse	"Несуществующие или чужие теги, связанные заметки или тема INVALID_REFERENCES"
 // @Failure		500	{object}	response.ErrorResponse	"Ошибка сервера"
 // @Router			/notes/create [post]
 func CreateNoteHandler(c *gin.Context) {
@@ -57,37 +65,53 @@ func CreateNoteHandler(c *gin.Context) {
 		return
 	}
 
-	// 2) Генерация embedding
-	embedding, err := service.GenerateEmbedding(input.Content)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Message: "Ошибка генерации эмбеддинга",
-			Code:    "EMBEDDING_ERROR",
-			Details: err.Error(),
-		})
-		return
+	// 2) Подготовка модели заметки. Эмбеддинг и резюме считаются в фоне
+	note := models.Note{
+		UserID:          userID,
+		Title:           input.Title,
+		Content:         input.Content,
+		ContentHash:     contentHash(input.Content),
+		RelatedIDs:      pq.Int64Array(input.RelatedIDs),
+		EmbeddingStatus: models.AIStatusPending,
+		SummaryStatus:   models.AIStatusPending,
 	}
-	embBytes, err := json.Marshal(embedding)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Message: "Ошибка сериализации эмбеддинга",
-			Code:    "EMBEDDING_SERIALIZE_ERROR",
-			Details: err.Error(),
-		})
-		return
+	if input.TopicID != 0 {
+		note.TopicID = &input.TopicID
 	}
 
-	// 3) Подготовка модели заметки
-	note := models.Note{
-		UserID:     userID,
-		Title:      input.Title,
-		Content:    input.Content,
-		Embedding:  embBytes,
-		RelatedIDs: pq.Int64Array(input.RelatedIDs),
-	}
+	// 3) В одной транзакции: проверка ссылок, заметка, теги, вложения, первая ревизия и задачи AI-очереди.
+	// Файлы вложений сохраняются до фиксации, поэтому при откате удаляются
+	var savedFiles []string
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := validateNoteRefs(tx, userID, 0, input.TagIDs, input.RelatedIDs, input.TopicID); err != nil {
+			return err
+		}
+		if err := tx.Create(&note).Error; err != nil {
+			return err
+		}
+		for _, tagID := range uniqueUints(input.TagIDs) {
+			if err := tx.Exec("INSERT INTO note_tags (note_id, tag_id) VALUES (?, ?)", note.ID, tagID).Error; err != nil {
+				return err
+			}
+		}
+		var err error
+		savedFiles, err = saveNoteAttachments(c, tx, note)
+		if err != nil {
+			return err
+		}
+		if err := saveRevision(tx, note, models.RevisionActionCreated); err != nil {
+			return err
+		}
+		return enqueueNoteAIJobs(tx, note, models.AIJobEmbed, models.AIJobSummarize)
+	})
+	if err != nil {
+		removeFiles(savedFiles)
 
-	// 4) Сохраняем заметку, чтобы получить note.ID (без тегов)
-	if err := db.DB.Create(&note).Error; err != nil {
+		var refsErr *invalidNoteRefsError
+		if errors.As(err, &refsErr) {
+			c.JSON(invalidNoteRefsResponse(refsErr))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
 			Message: "Ошибка при создании заметки",
 			Code:    "DB_ERROR",
@@ -96,63 +120,18 @@ func CreateNoteHandler(c *gin.Context) {
 		return
 	}
 
-	// 5) Если указаны теги — подгружаем их из БД и связываем
-	if len(input.TagIDs) > 0 {
-		// Создаем записи в промежуточной таблице note_tags
-		for _, tagID := range input.TagIDs {
-			if err := db.DB.Exec("INSERT INTO note_tags (note_id, tag_id) VALUES (?, ?)", note.ID, tagID).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-					Message: "Ошибка при связывании заметки с тегами",
-					Code:    "DB_ERROR",
-					Details: err.Error(),
-				})
-				return
-			}
-		}
-	}
-
-	// 6) Обработка файлов attachments (поле formData file, multi)
-	form, err := c.MultipartForm()
-	if err == nil && form.File["attachments"] != nil {
-		for _, fh := range form.File["attachments"] {
-			ext := strings.ToLower(filepath.Ext(fh.Filename))
-			var fType string
-			switch ext {
-			case ".png", ".jpg", ".jpeg", ".gif":
-				fType = "image"
-			case ".mp3", ".wav", ".ogg":
-				fType = "audio"
-			case ".pdf":
-				fType = "pdf"
-			default:
-				// пропускаем неподдерживаемый формат
-				continue
-			}
-
-			// генерируем уникальное имя
-			newName := fmt.Sprintf("%d_%s%s", userID, uuid.New().String(), ext)
-			dst := filepath.Join(config.UploadsPath+"/attachments", newName)
-			if err := c.SaveUploadedFile(fh, dst); err != nil {
-				// просто логируем и продолжаем
-				fmt.Printf("file save error: %v\n", err)
-				continue
-			}
-
-			url := fmt.Sprintf("/attachments/%s", newName)
-			att := models.Attachment{
-				NoteID:     note.ID,
-				FileURL:    url,
-				FileType:   fType,
-				FileSize:   fh.Size,
-				UploadedAt: time.Now(),
-			}
-			db.DB.Create(&att)
-		}
+	// 4) Предупреждение о точных дубликатах: заметка всё равно создаётся.
+	// Почти дубликаты ищет фоновая задача эмбеддингов и сохраняет в duplicate_ids заметки
+	duplicateIDs, err := findExactDuplicates(note)
+	if err != nil {
+		log.Printf("Ошибка поиска дубликатов заметки %d: %v", note.ID, err)
 	}
 
-	// 7) Ответ
-	c.JSON(http.StatusCreated, response.SuccessResponse{
-		Message: "Заметка успешно создана",
+	// 5) Ответ
+	c.JSON(http.StatusCreated, response.NoteCreatedResponse{
+		Message:      "Заметка успешно создана",
+		ID:           note.ID,
+		DuplicateIDs: duplicateIDs,
 	})
 }
 
@@ -162,11 +141,15 @@ func CreateNoteHandler(c *gin.Context) {
 // @Description	Генерирует краткое резюме для заметки пользователя по её ID
 // @Tags			note
 // @Accept			json
-// @Produce		json
+// @Produce		json,text/event-stream
 // @Param			id	path		uint	true	"ID заметки"
-// @Success		200		{object}	response.SummarizeResponse	"Резюме успешно сгенерировано"
+// @Success		200		{object}	response.SummarizeResponse	"Резюме успешно сгенерировано. При Accept: text/event-stream — события delta с фрагментами и done с результатом"
 // @Failure		404		{object}	response.ErrorResponse	"Заметка не найдена NOTE_NOT_FOUND"
+// @Failure		409		{object}	response.ErrorResponse	"Заметка изменилась во время генерации резюме NOTE_CHANGED"
 // @Failure		500		{object}	response.ErrorResponse	"Ошибка генерации резюме SUMMARY_ERROR, Ошибка сохранения резюме SUMMARY_SAVE_ERROR"
+// @Failure		429		{object}	response.ErrorResponse	"Превышен лимит запросов к модели AI_RATE_LIMITED"
+// @Failure		503		{object}	response.ErrorResponse	"Модель недоступна AI_UNAVAILABLE"
+// @Failure		504		{object}	response.ErrorResponse	"Модель не ответила вовремя AI_TIMEOUT"
 // @Router			/notes/{id}/summarize [post]
 func SummarizeNoteByIDHandler(c *gin.Context) {
 	userID := c.GetUint("userID")
@@ -181,44 +164,346 @@ func SummarizeNoteByIDHandler(c *gin.Context) {
 		return
 	}
 
+	if wantsEventStream(c) {
+		summarizeNoteStream(c, &note)
+		return
+	}
+
 	summary, err := service.SummarizeText(note.Content)
 	if err != nil {
+		c.JSON(aiError(err, "Ошибка генерации резюме", "SUMMARY_ERROR"))
+		return
+	}
+
+	if err := saveNoteSummary(&note, summary); err != nil {
+		c.JSON(summarySaveError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SummarizeResponse{Summary: summary})
+}
+
+// errNoteChanged — содержимое заметки изменилось, пока генерировалось резюме
+var errNoteChanged = errors.New("note content changed during summarization")
+
+// saveNoteSummary сохраняет новое резюме заметки. Предыдущее резюме остаётся в истории ревизий.
+// Если заметку отредактировали, пока генерировалось резюме, оно не сохраняется и возвращается errNoteChanged:
+// правка уже пометила прежнее резюме устаревшим, а резюме старого содержимого не должно выдаваться за актуальное
+func saveNoteSummary(note *models.Note, summary string) error {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Note{}).
+			Where("id = ? AND content = ?", note.ID, note.Content).
+			Updates(map[string]interface{}{
+				"summary":        summary,
+				"summary_stale":  false,
+				"summary_status": models.AIStatusReady,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errNoteChanged
+		}
+		return saveRevision(tx, *note, models.RevisionActionSummarized)
+	})
+	if err != nil {
+		return err
+	}
+	note.Summary = summary
+	note.SummaryStale = false
+	note.SummaryStatus = models.AIStatusReady
+	return nil
+}
+
+// summarySaveError формирует ответ на ошибку сохранения резюме
+func summarySaveError(err error) (int, response.ErrorResponse) {
+	if errors.Is(err, errNoteChanged) {
+		return http.StatusConflict, response.ErrorResponse{
+			Message: "Заметка изменилась во время генерации резюме, повторите запрос",
+			Code:    "NOTE_CHANGED",
+		}
+	}
+	return http.StatusInternalServerError, response.ErrorResponse{
+		Message: "Ошибка сохранения резюме",
+		Code:    "SUMMARY_SAVE_ERROR",
+		Details: err.Error(),
+	}
+}
+
+// UpdateNoteInput структура для частичного обновления заметки.
+// Поля, которые не переданы, остаются без изменений
+type UpdateNoteInput struct {
+	Title      *string  `json:"title,omitempty"`
+	Content    *string  `json:"content,omitempty"`
+	TagIDs     *[]uint  `json:"tag_ids,omitempty"`     // Полностью заменяет набор тегов
+	RelatedIDs *[]int64 `json:"related_ids,omitempty"` // Полностью заменяет список связанных заметок
+}
+
+// UpdateNoteHandler godoc
+// @Security		BearerAuth
+// @Summary		Редактирование заметки
+// @Description	Частично обновляет заметку. При изменении содержимого ставит пересчёт эмбеддинга в очередь и помечает резюме устаревшим
+// @Tags			note
+// @Accept			json
+// @Produce		json
+// @Param			id		path		uint			true	"ID заметки"
+// @Param			note	body		UpdateNoteInput	true	"Изменяемые поля"
+// @Success		200		{object}	response.NoteResponse	"Обновлённая заметка"
+// @Failure		400		{object}	response.ErrorResponse	"Ошибка валидации VALIDATION_ERROR"
+// @Failure		404		{object}	response.ErrorResponse	"Заметка не найдена NOTE_NOT_FOUND"
+// @Failure		422		{object}	response.InvalidReferencesResponse	"Несуществующие или чужие теги или связанные заметки INVALID_REFERENCES"
+// @Failure		500		{object}	response.ErrorResponse	"Ошибка БД DB_ERROR"
+// @Router			/notes/{id} [put]
+// @Router			/notes/{id} [patch]
+func UpdateNoteHandler(c *gin.Context) {
+	noteID := c.Param("id")
+	userID := c.GetUint("userID")
+
+	var input UpdateNoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	if (input.Title != nil && strings.TrimSpace(*input.Title) == "") ||
+		(input.Content != nil && strings.TrimSpace(*input.Content) == "") {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Заголовок и содержимое не могут быть пустыми",
+			Code:    "VALIDATION_ERROR",
+		})
+		return
+	}
+
+	tx := db.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Заметка читается под блокировкой строки: параллельное сохранение резюме
+	// не должно вклиниться между чтением и записью правки
+	var note models.Note
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ?", noteID, userID).First(&note).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, response.ErrorResponse{
+				Message: "Заметка не найдена",
+				Code:    "NOTE_NOT_FOUND",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Message: "Ошибка генерации резюме",
-			Code:    "SUMMARY_ERROR",
+			Message: "Ошибка при получении заметки",
+			Code:    "DB_ERROR",
 			Details: err.Error(),
 		})
 		return
 	}
 
-	note.Summary = summary
-	if err := db.DB.Model(&note).Update("summary", summary).Error; err != nil {
+	// 1) Собираем изменённые поля
+	updates := map[string]interface{}{}
+	if input.Title != nil {
+		updates["title"] = *input.Title
+		note.Title = *input.Title
+	}
+	if input.RelatedIDs != nil {
+		updates["related_ids"] = pq.Int64Array(*input.RelatedIDs)
+	}
+
+	// 2) При изменении содержимого эмбеддинг пересчитывается в фоне
+	contentChanged := input.Content != nil && *input.Content != note.Content
+	if contentChanged {
+		updates["content"] = *input.Content
+		updates["content_hash"] = contentHash(*input.Content)
+		note.Content = *input.Content
+		if note.Summary != "" {
+			updates["summary_stale"] = true
+			note.SummaryStale = true
+		}
+	}
+
+	// 3) Проверяем, что новые теги и связанные заметки принадлежат пользователю
+	var tagIDs []uint
+	var relatedIDs []int64
+	if input.TagIDs != nil {
+		tagIDs = *input.TagIDs
+	}
+	if input.RelatedIDs != nil {
+		relatedIDs = *input.RelatedIDs
+	}
+	if err := validateNoteRefs(tx, userID, note.ID, tagIDs, relatedIDs, 0); err != nil {
+		tx.Rollback()
+		var refsErr *invalidNoteRefsError
+		if errors.As(err, &refsErr) {
+			c.JSON(invalidNoteRefsResponse(refsErr))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Message: "Ошибка сохранения резюме",
-			Code:    "SUMMARY_SAVE_ERROR",
+			Message: "Ошибка при проверке тегов и связанных заметок",
+			Code:    "DB_ERROR",
 			Details: err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, response.SummarizeResponse{Summary: summary})
+	// 4) Обновляем поля заметки
+	if len(updates) > 0 {
+		if err := tx.Model(&note).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+				Message: "Ошибка при обновлении заметки",
+				Code:    "DB_ERROR",
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+
+	// 5) Заменяем набор тегов: снимаются только убранные и ставятся только новые теги,
+	// чтобы у оставшихся сохранилось время привязки (по нему считается last_used_at тега)
+	if input.TagIDs != nil {
+		tagIDs := uniqueUints(*input.TagIDs)
+		var removed *gorm.DB
+		if len(tagIDs) > 0 {
+			removed = tx.Exec("DELETE FROM note_tags WHERE note_id = ? AND tag_id NOT IN ?", note.ID, tagIDs)
+		} else {
+			removed = tx.Exec("DELETE FROM note_tags WHERE note_id = ?", note.ID)
+		}
+		if err := removed.Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+				Message: "Ошибка при обновлении тегов",
+				Code:    "DB_ERROR",
+				Details: err.Error(),
+			})
+			return
+		}
+		for _, tagID := range tagIDs {
+			if err := tx.Exec("INSERT INTO note_tags (note_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", note.ID, tagID).Error; err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+					Message: "Ошибка при связывании заметки с тегами",
+					Code:    "DB_ERROR",
+					Details: err.Error(),
+				})
+				return
+			}
+		}
+	}
+
+	if _, ok := updates["title"]; ok || contentChanged {
+		if err := saveRevision(tx, note, models.RevisionActionUpdated); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+				Message: "Ошибка при сохранении ревизии",
+				Code:    "DB_ERROR",
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+
+	if contentChanged {
+		if err := enqueueNoteAIJobs(tx, note, models.AIJobEmbed); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+				Message: "Ошибка при постановке задачи в очередь",
+				Code:    "DB_ERROR",
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при фиксации транзакции",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// 6) Возвращаем актуальное состояние заметки
+	if err := db.DB.Where("id = ?", note.ID).Preload("Tags").Preload("Attachments").First(&note).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении заметки",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, noteToResponse(note))
 }
 
 // GetNotesHandler godoc
 // @Security		BearerAuth
 // @Summary		Получения списка заметок
-// @Description	Выдаёт список всех заметок авторизованного пользователя
+// @Description	Выдаёт страницу заметок авторизованного пользователя с фильтрами и сортировкой. Total — общее число подходящих заметок
 // @Tags note
 // @Accept json
 // @Produce json
+// @Param			limit			query	int			false	"Размер страницы (1-100, по умолчанию 20)"
+// @Param			cursor			query	string		false	"Курсор следующей страницы из next_cursor"
+// @Param			sort			query	string		false	"Поле сортировки"	Enums(created, updated, title)
+// @Param			order			query	string		false	"Направление сортировки"	Enums(asc, desc)
+// @Param			tag_ids			query	[]int		false	"ID тегов"	collectionFormat(multi)
+// @Param			tag_mode		query	string		false	"any — любой из тегов, all — все теги"	Enums(any, all)
+// @Param			topic_id		query	int			false	"ID темы"
+// @Param			with_subtopics	query	bool		false	"Учитывать заметки во вложенных темах (по умолчанию true)"
+// @Param			archived		query	bool		false	"Только архивные (true) или только активные (false)"
+// @Param			has_attachments	query	bool		false	"Наличие вложений"
+// @Param			created_from	query	string		false	"Создана не раньше (YYYY-MM-DD)"
+// @Param			created_to		query	string		false	"Создана не позже (YYYY-MM-DD)"
+// @Param			updated_from	query	string		false	"Изменена не раньше (YYYY-MM-DD)"
+// @Param			updated_to		query	string		false	"Изменена не позже (YYYY-MM-DD)"
 // @Success 200 {object} response.NotesListResponse "Список заметок"
+// @Failure 400 {object} response.ErrorResponse "Ошибка валидации VALIDATION_ERROR, неверный курсор INVALID_CURSOR"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при получении заметок: DB_ERROR"
 // @Router			/notes/list [get]
 func GetNotesHandler(c *gin.Context) {
 	userID := c.GetUint("userID")
 
+	var input ListNotesInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	input.setDefaults()
+
+	// 1) Общее число подходящих заметок
+	var total int64
+	if err := applyNoteFilters(db.DB.Model(&models.Note{}).Where("notes.user_id = ?", userID), input).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении заметок",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// 2) Страница заметок
+	query, err := applyNoteCursor(applyNoteFilters(db.DB.Where("notes.user_id = ?", userID), input), input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Неверный курсор",
+			Code:    "INVALID_CURSOR",
+		})
+		return
+	}
+
 	var notes []models.Note
-	if err := db.DB.Where("user_id = ?", userID).Preload("Tags").Preload("Attachments").Find(&notes).Error; err != nil {
+	if err := query.Preload("Tags").Preload("Attachments").Find(&notes).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
 			Message: "Ошибка при получении заметок",
 			Code:    "DB_ERROR",
@@ -227,43 +512,21 @@ func GetNotesHandler(c *gin.Context) {
 		return
 	}
 
-	var notesResp []response.NoteResponse
+	var nextCursor string
+	if len(notes) > input.Limit {
+		notes = notes[:input.Limit]
+		nextCursor = nextNoteCursor(input.Sort, notes[len(notes)-1])
+	}
+
+	notesResp := make([]response.NoteResponse, 0, len(notes))
 	for _, note := range notes {
-		// Преобразование вложений
-		var attachments []response.AttachmentShort
-		for _, att := range note.Attachments {
-			attachments = append(attachments, response.AttachmentShort{
-				ID:       att.ID,
-				FileURL:  att.FileURL,
-				FileType: att.FileType,
-				FileSize: att.FileSize,
-			})
-		}
-		// Преобразование тегов
-		var tags []response.TagShort
-		for _, tag := range note.Tags {
-			tags = append(tags, response.TagShort{
-				ID:   tag.ID,
-				Name: tag.Name,
-			})
-		}
-		notesResp = append(notesResp, response.NoteResponse{
-			ID:          note.ID,
-			Title:       note.Title,
-			Content:     note.Content,
-			Summary:     note.Summary,
-			Attachments: attachments,
-			IsArchived:  note.IsArchived,
-			Tags:        tags,
-			RelatedIDs:  note.RelatedIDs,
-			CreatedAt:   note.CreatedAt.Format("2006-01-02"),
-			UpdatedAt:   note.UpdatedAt.Format("2006-01-02"),
-		})
+		notesResp = append(notesResp, noteToResponse(note))
 	}
 
 	c.JSON(http.StatusOK, response.NotesListResponse{
-		Notes: notesResp,
-		Total: len(notesResp),
+		Notes:      notesResp,
+		Total:      int(total),
+		NextCursor: nextCursor,
 	})
 }
 
@@ -291,36 +554,7 @@ func GetNoteHandler(c *gin.Context) {
 		return
 	}
 
-	var attachments []response.AttachmentShort
-	for _, att := range note.Attachments {
-		attachments = append(attachments, response.AttachmentShort{
-			ID:       att.ID,
-			FileURL:  att.FileURL,
-			FileType: att.FileType,
-			FileSize: att.FileSize,
-		})
-	}
-
-	var tags []response.TagShort
-	for _, tag := range note.Tags {
-		tags = append(tags, response.TagShort{
-			ID:   tag.ID,
-			Name: tag.Name,
-		})
-	}
-
-	c.JSON(http.StatusOK, response.NoteResponse{
-		ID:          note.ID,
-		Title:       note.Title,
-		Content:     note.Content,
-		Summary:     note.Summary,
-		IsArchived:  note.IsArchived,
-		Tags:        tags,
-		Attachments: attachments,
-		RelatedIDs:  note.RelatedIDs,
-		CreatedAt:   note.CreatedAt.Format("2006-01-02"),
-		UpdatedAt:   note.UpdatedAt.Format("2006-01-02"),
-	})
+	c.JSON(http.StatusOK, noteToResponse(note))
 }
 
 // ArchiveNoteHandler godoc
@@ -355,7 +589,14 @@ func ArchiveNoteHandler(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Model(&note).Update("is_archived", true).Error; err != nil {
+	// Архивация и её ревизия сохраняются вместе
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&note).Update("is_archived", true).Error; err != nil {
+			return err
+		}
+		return saveRevision(tx, note, models.RevisionActionArchived)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
 			Message: "Ошибка при архивировании заметки",
 			Code:    "DB_ERROR",
@@ -372,12 +613,12 @@ func ArchiveNoteHandler(c *gin.Context) {
 // DeleteNoteHandler godoc
 // @Security		BearerAuth
 // @Summary		Удаление заметки
-// @Description	Удаляет заметку пользователя по id и все связанные вложения
+// @Description	Перемещает заметку пользователя в корзину. Теги и вложения сохраняются до окончательного удаления
 // @Tags note
 // @Accept json
 // @Produce json
 // @Param			id	path		uint	true	"ID заметки"
-// @Success 200 {object} response.SuccessResponse "Заметка успешно удалена"
+// @Success 200 {object} response.SuccessResponse "Заметка перемещена в корзину"
 // @Failure 404 {object} response.ErrorResponse "Заметка не найдена NOTE_NOT_FOUND"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при удалении заметки DB_ERROR"
 // @Router	/notes/{id} [delete]
@@ -385,16 +626,8 @@ func DeleteNoteHandler(c *gin.Context) {
 	noteID := c.Param("id")
 	userID := c.GetUint("userID")
 
-	// Начинаем транзакцию для обеспечения целостности данных
-	tx := db.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
 	var note models.Note
-	if err := tx.Where("id = ? AND user_id = ?", noteID, userID).Preload("Attachments").First(&note).Error; err != nil {
+	if err := db.DB.Where("id = ? AND user_id = ?", noteID, userID).First(&note).Error; err != nil {
 		c.JSON(http.StatusNotFound, response.ErrorResponse{
 			Message: "Заметка не найдена",
 			Code:    "NOTE_NOT_FOUND",
@@ -402,64 +635,144 @@ func DeleteNoteHandler(c *gin.Context) {
 		return
 	}
 
-	// 1. Удаляем связи между заметкой и тегами в промежуточной таблице
-	if err := tx.Exec("DELETE FROM note_tags WHERE note_id = ?", note.ID).Error; err != nil {
-		tx.Rollback()
+	// Мягкое удаление: связи с тегами и файлы вложений остаются,
+	// чтобы заметку можно было восстановить из корзины
+	if err := db.DB.Delete(&note).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Message: "Ошибка при удалении связей с тегами",
+			Message: "Ошибка при удалении заметки",
 			Code:    "DB_ERROR",
 			Details: err.Error(),
 		})
 		return
 	}
 
-	// 2. Удаляем физические файлы вложений
-	for _, attachment := range note.Attachments {
-		// Извлекаем имя файла из URL
-		fileURL := attachment.FileURL
-		fileName := filepath.Base(fileURL)
-
-		filePath := filepath.Join(config.UploadsPath, "attachments", fileName)
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Message: "Заметка перемещена в корзину",
+	})
+}
 
-		if err := os.Remove(filePath); err != nil {
-			// Логируем ошибку, но продолжаем выполнение
-			fmt.Printf("Error deleting file %s: %v\n", filePath, err)
-		}
+// noteToResponse преобразует модель заметки (с подгруженными тегами и вложениями) в ответ API
+func noteToResponse(note models.Note) response.NoteResponse {
+	var attachments []response.AttachmentShort
+	for _, att := range note.Attachments {
+		attachments = append(attachments, response.AttachmentShort{
+			ID:       att.ID,
+			FileURL:  att.FileURL,
+			FileType: att.FileType,
+			FileSize: att.FileSize,
+		})
 	}
 
-	// 3. Удаляем вложения из базы данных
-	if err := tx.Where("note_id = ?", note.ID).Delete(&models.Attachment{}).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Message: "Ошибка при удалении вложений",
-			Code:    "DB_ERROR",
-			Details: err.Error(),
+	var tags []response.TagShort
+	for _, tag := range note.Tags {
+		tags = append(tags, response.TagShort{
+			ID:    tag.ID,
+			Name:  tag.Name,
+			Path:  tag.Path,
+			Color: tag.Color,
+			Icon:  tag.Icon,
 		})
-		return
 	}
 
-	// 4. Удаляем саму заметку
-	if err := tx.Delete(&note).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Message: "Ошибка при удалении заметки",
-			Code:    "DB_ERROR",
-			Details: err.Error(),
-		})
-		return
+	var topicID uint
+	if note.TopicID != nil {
+		topicID = *note.TopicID
 	}
 
-	// Фиксируем транзакцию
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Message: "Ошибка при фиксации транзакции",
-			Code:    "DB_ERROR",
-			Details: err.Error(),
-		})
-		return
+	return response.NoteResponse{
+		ID:              note.ID,
+		Title:           note.Title,
+		Content:         note.Content,
+		Summary:         note.Summary,
+		SummaryStale:    note.SummaryStale,
+		SummaryStatus:   note.SummaryStatus,
+		EmbeddingStatus: note.EmbeddingStatus,
+		TopicID:         topicID,
+		Attachments:     attachments,
+		IsArchived:      note.IsArchived,
+		Tags:            tags,
+		RelatedIDs:      note.RelatedIDs,
+		DuplicateIDs:    note.DuplicateIDs,
+		CreatedAt:       note.CreatedAt.Format("2006-01-02"),
+		UpdatedAt:       note.UpdatedAt.Format("2006-01-02"),
+	}
+}
+
+// saveNoteAttachments сохраняет файлы из поля attachments и записи о них в транзакции tx.
+// Неподдерживаемые форматы пропускаются. Возвращает пути сохранённых файлов, в том числе при ошибке,
+// чтобы вызывающий удалил их при откате
+func saveNoteAttachments(c *gin.Context, tx *gorm.DB, note models.Note) ([]string, error) {
+	form, err := c.MultipartForm()
+	if err != nil || form.File["attachments"] == nil {
+		return nil, nil
 	}
 
-	c.JSON(http.StatusOK, response.SuccessResponse{
-		Message: "Заметка и все связанные данные успешно удалены",
-	})
+	var saved []string
+	for _, fh := range form.File["attachments"] {
+		ext := strings.ToLower(filepath.Ext(fh.Filename))
+		var fType string
+		switch ext {
+		case ".png", ".jpg", ".jpeg", ".gif":
+			fType = "image"
+		case ".mp3", ".wav", ".ogg":
+			fType = "audio"
+		case ".pdf":
+			fType = "pdf"
+		default:
+			// пропускаем неподдерживаемый формат
+			continue
+		}
+
+		// генерируем уникальное имя
+		newName := fmt.Sprintf("%d_%s%s", note.UserID, uuid.New().String(), ext)
+		dst := filepath.Join(config.UploadsPath+"/attachments", newName)
+		if err := c.SaveUploadedFile(fh, dst); err != nil {
+			return saved, fmt.Errorf("save attachment %s: %w", fh.Filename, err)
+		}
+		saved = append(saved, dst)
+
+		att := models.Attachment{
+			NoteID:     note.ID,
+			FileURL:    fmt.Sprintf("/attachments/%s", newName),
+			FileType:   fType,
+			FileSize:   fh.Size,
+			UploadedAt: time.Now(),
+		}
+		if err := tx.Create(&att).Error; err != nil {
+			return saved, err
+		}
+	}
+	return saved, nil
+}
+
+// removeFiles удаляет файлы, сохранённые в откатившейся транзакции
+func removeFiles(paths []string) {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Ошибка удаления файла %s: %v", path, err)
+		}
+	}
+}
+
+// enqueueNoteAIJobs ставит в очередь AI-задачи указанных типов для заметки
+func enqueueNoteAIJobs(tx *gorm.DB, note models.Note, jobTypes ...string) error {
+	for _, jobType := range jobTypes {
+		if err := worker.Enqueue(tx, jobType, note.ID, note.UserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// embedContent строит эмбеддинг текста и его JSON-представление для колонки embedding
+func embedContent(text string) ([]float64, []byte, error) {
+	embedding, err := service.GenerateDocumentEmbedding(text)
+	if err != nil {
+		return nil, nil, err
+	}
+	embBytes, err := json.Marshal(embedding)
+	if err != nil {
+		return nil, nil, err
+	}
+	return embedding, embBytes, nil
 }
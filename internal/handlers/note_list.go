@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"NeuroNest/internal/models"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ListNotesInput параметры пагинации, фильтрации и сортировки списка заметок (query string)
+type ListNotesInput struct {
+	Limit          int       `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor         string    `form:"cursor"`
+	Sort           string    `form:"sort" binding:"omitempty,oneof=created updated title"`
+	Order          string    `form:"order" binding:"omitempty,oneof=asc desc"`
+	TagIDs         []uint    `form:"tag_ids"`
+	TagMode        string    `form:"tag_mode" binding:"omitempty,oneof=any all"`
+	TopicID        uint      `form:"topic_id"`
+	WithSubtopics  *bool     `form:"with_subtopics"` // По умолчанию true: заметки во вложенных темах тоже подходят
+	Archived       *bool     `form:"archived"`
+	HasAttachments *bool     `form:"has_attachments"`
+	CreatedFrom    time.Time `form:"created_from" time_format:"2006-01-02"`
+	CreatedTo      time.Time `form:"created_to" time_format:"2006-01-02"`
+	UpdatedFrom    time.Time `form:"updated_from" time_format:"2006-01-02"`
+	UpdatedTo      time.Time `form:"updated_to" time_format:"2006-01-02"`
+}
+
+// noteSortColumns соответствие параметра sort колонке таблицы notes
+var noteSortColumns = map[string]string{
+	"created": "created_at",
+	"updated": "updated_at",
+	"title":   "title",
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// noteCursor — позиция последней заметки страницы для keyset-пагинации
+type noteCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+func (in *ListNotesInput) setDefaults() {
+	if in.Limit == 0 {
+		in.Limit = 20
+	}
+	if in.Sort == "" {
+		in.Sort = "created"
+	}
+	if in.Order == "" {
+		in.Order = "desc"
+	}
+	if in.TagMode == "" {
+		in.TagMode = "any"
+	}
+}
+
+// applyNoteFilters добавляет к запросу фильтры списка заметок (без курсора и сортировки)
+func applyNoteFilters(q *gorm.DB, in ListNotesInput) *gorm.DB {
+	// Тег подходит вместе со всеми вложенными в него тегами
+	if len(in.TagIDs) > 0 {
+		tagged := "SELECT note_tags.note_id FROM note_tags JOIN tags d ON d.id = note_tags.tag_id JOIN tags t ON " + tagDescendantsSQL + " WHERE t.id IN ?"
+		if in.TagMode == "all" {
+			q = q.Where("notes.id IN ("+tagged+" GROUP BY note_tags.note_id HAVING COUNT(DISTINCT t.id) = ?)",
+				in.TagIDs, countDistinct(in.TagIDs))
+		} else {
+			q = q.Where("notes.id IN ("+tagged+")", in.TagIDs)
+		}
+	}
+	if in.TopicID != 0 {
+		if in.WithSubtopics == nil || *in.WithSubtopics {
+			q = q.Where("notes.topic_id IN ("+topicSubtreeSQL+")", in.TopicID)
+		} else {
+			q = q.Where("notes.topic_id = ?", in.TopicID)
+		}
+	}
+	if in.Archived != nil {
+		q = q.Where("notes.is_archived = ?", *in.Archived)
+	}
+	if in.HasAttachments != nil {
+		exists := "EXISTS (SELECT 1 FROM attachments WHERE attachments.note_id = notes.id AND attachments.deleted_at IS NULL)"
+		if *in.HasAttachments {
+			q = q.Where(exists)
+		} else {
+			q = q.Where("NOT " + exists)
+		}
+	}
+	// Границы дат включительные: "to" означает до конца указанного дня
+	if !in.CreatedFrom.IsZero() {
+		q = q.Where("notes.created_at >= ?", in.CreatedFrom)
+	}
+	if !in.CreatedTo.IsZero() {
+		q = q.Where("notes.created_at < ?", in.CreatedTo.AddDate(0, 0, 1))
+	}
+	if !in.UpdatedFrom.IsZero() {
+		q = q.Where("notes.updated_at >= ?", in.UpdatedFrom)
+	}
+	if !in.UpdatedTo.IsZero() {
+		q = q.Where("notes.updated_at < ?", in.UpdatedTo.AddDate(0, 0, 1))
+	}
+	return q
+}
+
+// applyNoteCursor добавляет сортировку, условие курсора и лимит (+1 запись для определения следующей страницы)
+func applyNoteCursor(q *gorm.DB, in ListNotesInput) (*gorm.DB, error) {
+	column := noteSortColumns[in.Sort]
+	direction := "DESC"
+	cmp := "<"
+	if in.Order == "asc" {
+		direction = "ASC"
+		cmp = ">"
+	}
+
+	if in.Cursor != "" {
+		cur, err := decodeNoteCursor(in.Cursor)
+		if err != nil || cur.Sort != in.Sort {
+			return nil, errInvalidCursor
+		}
+		value, err := cur.sortValue()
+		if err != nil {
+			return nil, errInvalidCursor
+		}
+		q = q.Where(fmt.Sprintf("(notes.%s, notes.id) %s (?, ?)", column, cmp), value, cur.ID)
+	}
+
+	return q.Order(fmt.Sprintf("notes.%s %s, notes.id %s", column, direction, direction)).Limit(in.Limit + 1), nil
+}
+
+// nextNoteCursor строит курсор на следующую страницу по последней заметке текущей
+func nextNoteCursor(sort string, last models.Note) string {
+	cur := noteCursor{Sort: sort, ID: last.ID}
+	switch sort {
+	case "updated":
+		cur.Value = last.UpdatedAt.Format(time.RFC3339Nano)
+	case "title":
+		cur.Value = last.Title
+	default:
+		cur.Value = last.CreatedAt.Format(time.RFC3339Nano)
+	}
+	raw, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeNoteCursor(s string) (noteCursor, error) {
+	var cur noteCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cur, err
+	}
+	err = json.Unmarshal(raw, &cur)
+	return cur, err
+}
+
+func (cur noteCursor) sortValue() (interface{}, error) {
+	if cur.Sort == "title" {
+		return cur.Value, nil
+	}
+	return time.Parse(time.RFC3339Nano, cur.Value)
+}
+
+func countDistinct(ids []uint) int {
+	seen := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		seen[id] = struct{}{}
+	}
+	return len(seen)
+}
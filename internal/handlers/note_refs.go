@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/response"
+	"fmt"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// invalidNoteRefsError — заметка ссылается на теги, заметки или тему, которых нет у пользователя
+type invalidNoteRefsError struct {
+	TagIDs     []uint
+	RelatedIDs []int64
+	TopicID    uint
+}
+
+func (e *invalidNoteRefsError) Error() string {
+	return fmt.Sprintf("invalid references: tag_ids=%v related_ids=%v topic_id=%d", e.TagIDs, e.RelatedIDs, e.TopicID)
+}
+
+// validateNoteRefs проверяет в транзакции tx, что теги, связанные заметки и тема существуют и принадлежат
+// пользователю. noteID — сама заметка (0 при создании): ссылка заметки на себя тоже недопустима.
+// Возвращает *invalidNoteRefsError со всеми неверными ID
+func validateNoteRefs(tx *gorm.DB, userID, noteID uint, tagIDs []uint, relatedIDs []int64, topicID uint) error {
+	invalid := &invalidNoteRefsError{}
+
+	if len(tagIDs) > 0 {
+		var found []uint
+		if err := tx.Model(&models.Tag{}).Where("id IN ? AND user_id = ?", tagIDs, userID).Pluck("id", &found).Error; err != nil {
+			return err
+		}
+		owned := make(map[uint]bool, len(found))
+		for _, id := range found {
+			owned[id] = true
+		}
+		for _, id := range uniqueUints(tagIDs) {
+			if !owned[id] {
+				invalid.TagIDs = append(invalid.TagIDs, id)
+			}
+		}
+	}
+
+	if len(relatedIDs) > 0 {
+		var found []int64
+		if err := tx.Model(&models.Note{}).Where("id IN ? AND user_id = ? AND id <> ?", relatedIDs, userID, noteID).Pluck("id", &found).Error; err != nil {
+			return err
+		}
+		owned := make(map[int64]bool, len(found))
+		for _, id := range found {
+			owned[id] = true
+		}
+		seen := make(map[int64]bool, len(relatedIDs))
+		for _, id := range relatedIDs {
+			if !owned[id] && !seen[id] {
+				invalid.RelatedIDs = append(invalid.RelatedIDs, id)
+			}
+			seen[id] = true
+		}
+	}
+
+	if topicID != 0 {
+		var count int64
+		if err := tx.Model(&models.Topic{}).Where("id = ? AND user_id = ?", topicID, userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			invalid.TopicID = topicID
+		}
+	}
+
+	if len(invalid.TagIDs) > 0 || len(invalid.RelatedIDs) > 0 || invalid.TopicID != 0 {
+		return invalid
+	}
+	return nil
+}
+
+// invalidNoteRefsResponse ответ 422 со списком неверных ID
+func invalidNoteRefsResponse(err *invalidNoteRefsError) (int, response.InvalidReferencesResponse) {
+	return http.StatusUnprocessableEntity, response.InvalidReferencesResponse{
+		Message:           "Указаны несуществующие или чужие теги, заметки или тема",
+		Code:              "INVALID_REFERENCES",
+		InvalidTagIDs:     err.TagIDs,
+		InvalidRelatedIDs: err.RelatedIDs,
+		InvalidTopicID:    err.TopicID,
+	}
+}
+
+// uniqueUints убирает повторы, сохраняя порядок
+func uniqueUints(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
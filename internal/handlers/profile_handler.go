@@ -40,6 +40,9 @@ func GetProfileHandler(c *gin.Context) {
 		FirstName:  user.FirstName,
 		LastName:   user.LastName,
 		ProfilePic: user.ProfilePic,
+
+		AutoTagEnabled:   user.AutoTagEnabled,
+		AutoTagThreshold: user.AutoTagThreshold,
 	}
 	c.JSON(http.StatusOK, userRes)
 }
@@ -91,6 +94,12 @@ func UpdateProfileHandler(c *gin.Context) {
 	if input.ProfilePic != nil {
 		user.ProfilePic = *input.ProfilePic
 	}
+	if input.AutoTagEnabled != nil {
+		user.AutoTagEnabled = *input.AutoTagEnabled
+	}
+	if input.AutoTagThreshold != nil {
+		user.AutoTagThreshold = *input.AutoTagThreshold
+	}
 
 	if err := db.DB.Save(&user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
@@ -111,6 +120,9 @@ type UpdateProfileInput struct {
 	FirstName  *string `json:"first_name,omitempty"`
 	LastName   *string `json:"last_name,omitempty"`
 	ProfilePic *string `json:"profile_pic,omitempty"`
+
+	AutoTagEnabled   *bool    `json:"auto_tag_enabled,omitempty"`                                  // Автоматически проставлять предложенные теги новым заметкам
+	AutoTagThreshold *float64 `json:"auto_tag_threshold,omitempty" binding:"omitempty,gt=0,lte=1"` // Порог уверенности 0..1
 }
 
 // UploadAvatarHandler godoc
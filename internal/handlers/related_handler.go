@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"NeuroNest/internal/config"
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/response"
+	"NeuroNest/internal/search"
+	"NeuroNest/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Источники связанной заметки
+const (
+	relatedSourceConfirmed = "confirmed" // Указана пользователем в related_ids
+	relatedSourceSuggested = "suggested" // Найдена по сходству эмбеддингов
+)
+
+// refreshNoteRelations пересчитывает предложенные связанные заметки по эмбеддингу заметки
+// и добавляет заметку в списки её соседей, оставляя в каждом списке RelatedNotesCount лучших.
+// Заметки, в списках которых заметка была, но которые не попали в её соседи, пересчитывают свои списки:
+// ближайшие соседи несимметричны, и просто убрать из них заметку значило бы оставить их неполными
+func refreshNoteRelations(note models.Note, emb []float64, model string) error {
+	limit := config.RelatedNotesCount
+	if limit <= 0 {
+		return nil
+	}
+
+	relations, err := neighbourRelations(note.ID, note.UserID, emb, model, limit)
+	if err != nil {
+		return err
+	}
+	var referrers []uint
+	if err := db.DB.Model(&models.NoteRelation{}).Where("related_id = ?", note.ID).Pluck("note_id", &referrers).Error; err != nil {
+		return err
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		// 1) Собственный список заметки считается заново
+		if err := replaceNoteRelations(tx, note.ID, relations); err != nil {
+			return err
+		}
+
+		// 2) Сходство симметрично: заметка становится кандидатом в списки своих соседей
+		for _, rel := range relations {
+			reverse := models.NoteRelation{
+				NoteID:         rel.RelatedID,
+				RelatedID:      note.ID,
+				UserID:         note.UserID,
+				Score:          rel.Score,
+				EmbeddingModel: model,
+			}
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&reverse).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`DELETE FROM note_relations WHERE note_id = ? AND related_id NOT IN
+				(SELECT related_id FROM note_relations WHERE note_id = ? ORDER BY score DESC LIMIT ?)`,
+				rel.RelatedID, rel.RelatedID, limit).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 3) Остальные заметки, ссылавшиеся на эту, хранят сходство со старым эмбеддингом — пересчитываем их списки
+	neighbours := make(map[uint]bool, len(relations))
+	for _, rel := range relations {
+		neighbours[rel.RelatedID] = true
+	}
+	for _, id := range referrers {
+		if neighbours[id] {
+			continue
+		}
+		if err := recomputeNoteRelations(id, model, limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recomputeNoteRelations пересчитывает собственный список связанных заметки по её сохранённому эмбеддингу,
+// не трогая списки соседей. Заметки без эмбеддинга модели model пропускаются
+func recomputeNoteRelations(noteID uint, model string, limit int) error {
+	var note models.Note
+	err := db.DB.Select("id", "user_id", "embedding").
+		Where("id = ? AND embedding_model = ? AND embedding IS NOT NULL", noteID, model).
+		First(&note).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return db.DB.Where("note_id = ?", noteID).Delete(&models.NoteRelation{}).Error
+	}
+	if err != nil {
+		return err
+	}
+	var emb []float64
+	if err := json.Unmarshal(note.Embedding, &emb); err != nil {
+		return err
+	}
+
+	relations, err := neighbourRelations(note.ID, note.UserID, emb, model, limit)
+	if err != nil {
+		return err
+	}
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		return replaceNoteRelations(tx, note.ID, relations)
+	})
+}
+
+// neighbourRelations ближайшие соседи заметки со сходством не ниже RelatedMinSimilarity
+func neighbourRelations(noteID, userID uint, emb []float64, model string, limit int) ([]models.NoteRelation, error) {
+	hits, err := search.Neighbours(userID, noteID, emb, model, limit)
+	if err != nil {
+		return nil, err
+	}
+	relations := make([]models.NoteRelation, 0, len(hits))
+	for _, hit := range hits {
+		if hit.Score < config.RelatedMinSimilarity {
+			continue
+		}
+		relations = append(relations, models.NoteRelation{
+			NoteID:         noteID,
+			RelatedID:      hit.NoteID,
+			UserID:         userID,
+			Score:          hit.Score,
+			EmbeddingModel: model,
+		})
+	}
+	return relations, nil
+}
+
+// replaceNoteRelations заменяет собственный список связанных заметки
+func replaceNoteRelations(tx *gorm.DB, noteID uint, relations []models.NoteRelation) error {
+	if err := tx.Where("note_id = ?", noteID).Delete(&models.NoteRelation{}).Error; err != nil {
+		return err
+	}
+	if len(relations) == 0 {
+		return nil
+	}
+	return tx.Create(&relations).Error
+}
+
+// GetRelatedNotesHandler godoc
+// @Security		BearerAuth
+// @Summary		Связанные заметки
+// @Description	Объединяет связанные заметки, указанные пользователем (source=confirmed), с ближайшими по эмбеддингу (source=suggested). Сначала идут подтверждённые, затем предложенные по убыванию сходства. Несуществующие и чужие ID из related_ids пропускаются
+// @Tags			note
+// @Accept			json
+// @Produce		json
+// @Param			id	path		uint	true	"ID заметки"
+// @Success		200	{object}	response.RelatedNotesResponse	"Связанные заметки"
+// @Failure		404	{object}	response.ErrorResponse	"Заметка не найдена NOTE_NOT_FOUND"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка при получении связанных заметок DB_ERROR"
+// @Router			/notes/{id}/related [get]
+func GetRelatedNotesHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+	noteID := c.Param("id")
+
+	var note models.Note
+	if err := db.DB.Where("id = ? AND user_id = ?", noteID, userID).First(&note).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Заметка не найдена",
+			Code:    "NOTE_NOT_FOUND",
+		})
+		return
+	}
+
+	related, err := relatedNotes(note)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении связанных заметок",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.RelatedNotesResponse{
+		NoteID:  note.ID,
+		Related: related,
+		Total:   len(related),
+	})
+}
+
+// relatedNotes объединяет подтверждённые и предложенные связанные заметки
+func relatedNotes(note models.Note) ([]response.RelatedNote, error) {
+	// 1) Предложенные соседи по эмбеддингам текущей модели
+	q := db.DB.Table("note_relations").
+		Select("note_relations.related_id, note_relations.score").
+		Joins("JOIN notes ON notes.id = note_relations.related_id AND notes.deleted_at IS NULL AND notes.is_archived = ?", false).
+		Where("note_relations.note_id = ?", note.ID).
+		Order("note_relations.score DESC")
+	if model, err := service.EmbeddingModel(service.EmbeddingDocument); err == nil {
+		q = q.Where("note_relations.embedding_model = ?", model)
+	}
+	var suggested []struct {
+		RelatedID uint
+		Score     float64
+	}
+	if err := q.Scan(&suggested).Error; err != nil {
+		return nil, err
+	}
+	scores := make(map[uint]float64, len(suggested))
+	for _, s := range suggested {
+		scores[s.RelatedID] = s.Score
+	}
+
+	// 2) Подтверждённые пользователем — только существующие заметки того же пользователя
+	ids := make([]uint, 0, len(note.RelatedIDs)+len(suggested))
+	for _, id := range note.RelatedIDs {
+		ids = append(ids, uint(id))
+	}
+	for _, s := range suggested {
+		ids = append(ids, s.RelatedID)
+	}
+	var notes []models.Note
+	if len(ids) > 0 {
+		if err := db.DB.Select("id", "title").Where("id IN ? AND user_id = ?", ids, note.UserID).Find(&notes).Error; err != nil {
+			return nil, err
+		}
+	}
+	titles := make(map[uint]string, len(notes))
+	for _, n := range notes {
+		titles[n.ID] = n.Title
+	}
+
+	// 3) Сначала подтверждённые в порядке пользователя, затем оставшиеся предложенные
+	related := make([]response.RelatedNote, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	add := func(id uint, source string) {
+		title, ok := titles[id]
+		if !ok || seen[id] || id == note.ID {
+			return
+		}
+		seen[id] = true
+		item := response.RelatedNote{ID: id, Title: title, Source: source}
+		if score, ok := scores[id]; ok {
+			item.Score = &score
+		}
+		related = append(related, item)
+	}
+	for _, id := range note.RelatedIDs {
+		add(uint(id), relatedSourceConfirmed)
+	}
+	for _, s := range suggested {
+		add(s.RelatedID, relatedSourceSuggested)
+	}
+	return related, nil
+}
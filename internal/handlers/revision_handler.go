@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/response"
+	"NeuroNest/internal/textdiff"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RevisionDiffInput номера сравниваемых ревизий (query string)
+type RevisionDiffInput struct {
+	From int `form:"from" binding:"required,min=1"`
+	To   int `form:"to" binding:"required,min=1"`
+}
+
+// GetNoteRevisionsHandler godoc
+// @Security		BearerAuth
+// @Summary		История ревизий заметки
+// @Description	Возвращает список ревизий заметки от новых к старым (без содержимого)
+// @Tags			revision
+// @Accept			json
+// @Produce		json
+// @Param			id	path		uint	true	"ID заметки"
+// @Success		200	{object}	response.RevisionsListResponse	"Список ревизий"
+// @Failure		404	{object}	response.ErrorResponse	"Заметка не найдена NOTE_NOT_FOUND"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка при получении ревизий DB_ERROR"
+// @Router			/notes/{id}/revisions [get]
+func GetNoteRevisionsHandler(c *gin.Context) {
+	noteID := c.Param("id")
+	userID := c.GetUint("userID")
+
+	var note models.Note
+	if err := db.DB.Where("id = ? AND user_id = ?", noteID, userID).First(&note).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Заметка не найдена",
+			Code:    "NOTE_NOT_FOUND",
+		})
+		return
+	}
+
+	var revisions []models.NoteRevision
+	if err := db.DB.Where("note_id = ?", note.ID).Order("number DESC").Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении ревизий",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	revisionsResp := make([]response.RevisionResponse, 0, len(revisions))
+	for _, rev := range revisions {
+		revisionsResp = append(revisionsResp, response.RevisionResponse{
+			Number:    rev.Number,
+			Action:    rev.Action,
+			Title:     rev.Title,
+			CreatedAt: rev.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	c.JSON(http.StatusOK, response.RevisionsListResponse{
+		Revisions: revisionsResp,
+		Total:     len(revisionsResp),
+	})
+}
+
+// GetNoteRevisionHandler godoc
+// @Security		BearerAuth
+// @Summary		Получение ревизии заметки
+// @Description	Возвращает полный снимок заметки в указанной ревизии
+// @Tags			revision
+// @Accept			json
+// @Produce		json
+// @Param			id		path		uint	true	"ID заметки"
+// @Param			number	path		int		true	"Номер ревизии"
+// @Success		200		{object}	response.RevisionResponse	"Ревизия"
+// @Failure		404		{object}	response.ErrorResponse	"Заметка не найдена NOTE_NOT_FOUND, ревизия не найдена REVISION_NOT_FOUND"
+// @Router			/notes/{id}/revisions/{number} [get]
+func GetNoteRevisionHandler(c *gin.Context) {
+	noteID := c.Param("id")
+	userID := c.GetUint("userID")
+
+	var note models.Note
+	if err := db.DB.Where("id = ? AND user_id = ?", noteID, userID).First(&note).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Заметка не найдена",
+			Code:    "NOTE_NOT_FOUND",
+		})
+		return
+	}
+
+	var rev models.NoteRevision
+	if err := db.DB.Where("note_id = ? AND number = ?", note.ID, c.Param("number")).First(&rev).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Ревизия не найдена",
+			Code:    "REVISION_NOT_FOUND",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.RevisionResponse{
+		Number:       rev.Number,
+		Action:       rev.Action,
+		Title:        rev.Title,
+		Content:      rev.Content,
+		Summary:      rev.Summary,
+		SummaryStale: rev.SummaryStale,
+		CreatedAt:    rev.CreatedAt.Format("2006-01-02 15:04:05"),
+	})
+}
+
+// DiffNoteRevisionsHandler godoc
+// @Security		BearerAuth
+// @Summary		Сравнение ревизий заметки
+// @Description	Возвращает unified diff заголовка, содержимого и резюме между двумя ревизиями
+// @Tags			revision
+// @Accept			json
+// @Produce		json
+// @Param			id		path		uint	true	"ID заметки"
+// @Param			from	query		int		true	"Номер исходной ревизии"
+// @Param			to		query		int		true	"Номер конечной ревизии"
+// @Success		200		{object}	response.RevisionDiffResponse	"Diff между ревизиями"
+// @Failure		400		{object}	response.ErrorResponse	"Ошибка валидации VALIDATION_ERROR"
+// @Failure		404		{object}	response.ErrorResponse	"Заметка не найдена NOTE_NOT_FOUND, ревизия не найдена REVISION_NOT_FOUND"
+// @Router			/notes/{id}/revisions/diff [get]
+func DiffNoteRevisionsHandler(c *gin.Context) {
+	noteID := c.Param("id")
+	userID := c.GetUint("userID")
+
+	var input RevisionDiffInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var note models.Note
+	if err := db.DB.Where("id = ? AND user_id = ?", noteID, userID).First(&note).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Заметка не найдена",
+			Code:    "NOTE_NOT_FOUND",
+		})
+		return
+	}
+
+	var from, to models.NoteRevision
+	if err := db.DB.Where("note_id = ? AND number = ?", note.ID, input.From).First(&from).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Ревизия не найдена",
+			Code:    "REVISION_NOT_FOUND",
+			Details: fmt.Sprintf("from=%d", input.From),
+		})
+		return
+	}
+	if err := db.DB.Where("note_id = ? AND number = ?", note.ID, input.To).First(&to).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Ревизия не найдена",
+			Code:    "REVISION_NOT_FOUND",
+			Details: fmt.Sprintf("to=%d", input.To),
+		})
+		return
+	}
+
+	// Поля сравниваются по отдельности, как файлы в git diff
+	var diff strings.Builder
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"title", from.Title, to.Title},
+		{"content", from.Content, to.Content},
+		{"summary", from.Summary, to.Summary},
+	}
+	for _, f := range fields {
+		diff.WriteString(textdiff.Unified(
+			fmt.Sprintf("a/%s@%d", f.name, from.Number),
+			fmt.Sprintf("b/%s@%d", f.name, to.Number),
+			f.from, f.to,
+		))
+	}
+
+	c.JSON(http.StatusOK, response.RevisionDiffResponse{
+		From: from.Number,
+		To:   to.Number,
+		Diff: diff.String(),
+	})
+}
+
+// RestoreNoteRevisionHandler godoc
+// @Security		BearerAuth
+// @Summary		Восстановление ревизии заметки
+// @Description	Возвращает заметке заголовок, содержимое и резюме из указанной ревизии и сохраняет результат как новую ревизию
+// @Tags			revision
+// @Accept			json
+// @Produce		json
+// @Param			id		path		uint	true	"ID заметки"
+// @Param			number	path		int		true	"Номер ревизии"
+// @Success		200		{object}	response.NoteResponse	"Восстановленная заметка"
+// @Failure		404		{object}	response.ErrorResponse	"Заметка не найдена NOTE_NOT_FOUND, ревизия не найдена REVISION_NOT_FOUND"
+// @Failure		500		{object}	response.ErrorResponse	"Ошибка БД DB_ERROR"
+// @Router			/notes/{id}/revisions/{number}/restore [post]
+func RestoreNoteRevisionHandler(c *gin.Context) {
+	noteID := c.Param("id")
+	userID := c.GetUint("userID")
+
+	var note models.Note
+	if err := db.DB.Where("id = ? AND user_id = ?", noteID, userID).First(&note).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Заметка не найдена",
+			Code:    "NOTE_NOT_FOUND",
+		})
+		return
+	}
+
+	var rev models.NoteRevision
+	if err := db.DB.Where("note_id = ? AND number = ?", note.ID, c.Param("number")).First(&rev).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Ревизия не найдена",
+			Code:    "REVISION_NOT_FOUND",
+		})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"title":         rev.Title,
+		"summary":       rev.Summary,
+		"summary_stale": rev.SummaryStale,
+	}
+
+	// Эмбеддинг пересчитывается в фоне и только если содержимое действительно меняется
+	contentChanged := rev.Content != note.Content
+	if contentChanged {
+		updates["content"] = rev.Content
+		updates["content_hash"] = contentHash(rev.Content)
+	}
+
+	note.Title = rev.Title
+	note.Content = rev.Content
+	note.Summary = rev.Summary
+	note.SummaryStale = rev.SummaryStale
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&note).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := saveRevision(tx, note, models.RevisionActionRestored); err != nil {
+			return err
+		}
+		if contentChanged {
+			return enqueueNoteAIJobs(tx, note, models.AIJobEmbed)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при восстановлении ревизии",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := db.DB.Where("id = ?", note.ID).Preload("Tags").Preload("Attachments").First(&note).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении заметки",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, noteToResponse(note))
+}
+
+// saveRevision сохраняет текущие заголовок, содержимое и резюме заметки как новую ревизию.
+// Вызывается внутри транзакции после записи изменений: строка заметки блокируется до её конца,
+// чтобы параллельные записи (правка пользователя и сохранение резюме воркером) не получили
+// одинаковый номер ревизии. Снимок берётся из заблокированной строки, а не из note: структура
+// могла быть прочитана до начала транзакции и не содержать чужих правок
+func saveRevision(tx *gorm.DB, note models.Note, action string) error {
+	var current models.Note
+	if err := tx.Raw("SELECT id, user_id, title, content, summary, summary_stale FROM notes WHERE id = ? FOR UPDATE", note.ID).
+		Scan(&current).Error; err != nil {
+		return err
+	}
+	if current.ID == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	var last int
+	if err := tx.Model(&models.NoteRevision{}).
+		Where("note_id = ?", note.ID).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&last).Error; err != nil {
+		return err
+	}
+
+	rev := models.NoteRevision{
+		NoteID:       current.ID,
+		UserID:       current.UserID,
+		Number:       last + 1,
+		Title:        current.Title,
+		Content:      current.Content,
+		Summary:      current.Summary,
+		SummaryStale: current.SummaryStale,
+		Action:       action,
+	}
+	return tx.Create(&rev).Error
+}
@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"NeuroNest/internal/config"
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/response"
+	"NeuroNest/internal/search"
+	"NeuroNest/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SemanticSearchInput параметры семантического поиска (query string)
+type SemanticSearchInput struct {
+	Query           string `form:"q" binding:"required"`
+	Limit           int    `form:"limit" binding:"omitempty,min=1,max=50"`
+	IncludeArchived bool   `form:"include_archived"`
+}
+
+// TextSearchInput параметры полнотекстового поиска (query string)
+type TextSearchInput struct {
+	Query           string `form:"q" binding:"required"`
+	Limit           int    `form:"limit" binding:"omitempty,min=1,max=50"`
+	Offset          int    `form:"offset" binding:"omitempty,min=0"`
+	IncludeArchived bool   `form:"include_archived"`
+}
+
+// TextSearchHandler godoc
+// @Security		BearerAuth
+// @Summary		Полнотекстовый поиск по заметкам
+// @Description	Ищет по заголовку, содержимому и резюме с учётом морфологии русского и английского языков. Возвращает ранг и фрагменты с подсветкой <mark>: текст заметки в них HTML-экранирован, другой разметки нет
+// @Tags			note
+// @Accept			json
+// @Produce		json
+// @Param			q					query	string	true	"Поисковый запрос (синтаксис websearch: кавычки для фраз, or, минус для исключения)"
+// @Param			limit				query	int		false	"Максимальное число результатов (1-50, по умолчанию 20)"
+// @Param			offset				query	int		false	"Смещение"
+// @Param			include_archived	query	bool	false	"Искать также среди архивных заметок"
+// @Success		200	{object}	response.TextSearchResponse	"Найденные заметки"
+// @Failure		400	{object}	response.ErrorResponse	"Ошибка валидации VALIDATION_ERROR"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка БД DB_ERROR"
+// @Router			/notes/search [get]
+func TextSearchHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input TextSearchInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	if input.Limit == 0 {
+		input.Limit = 20
+	}
+
+	hits, err := search.FullText(userID, input.Query, search.TextOptions{
+		Limit:           input.Limit,
+		Offset:          input.Offset,
+		IncludeArchived: input.IncludeArchived,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при поиске заметок",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.NoteID)
+	}
+	notesByID, err := loadNotesByIDs(userID, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении заметок",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	results := make([]response.TextSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		note, ok := notesByID[hit.NoteID]
+		if !ok {
+			continue
+		}
+		results = append(results, response.TextSearchResult{
+			Note:           noteToResponse(note),
+			Rank:           hit.Rank,
+			TitleHighlight: hit.TitleHighlight,
+			Snippet:        hit.Snippet,
+		})
+	}
+
+	c.JSON(http.StatusOK, response.TextSearchResponse{
+		Results: results,
+		Total:   len(results),
+	})
+}
+
+// SemanticSearchHandler godoc
+// @Security		BearerAuth
+// @Summary		Семантический поиск по заметкам
+// @Description	Строит эмбеддинг запроса и возвращает заметки пользователя, отсортированные по косинусному сходству лучшего фрагмента. Для каждой заметки указан этот фрагмент и его смещения
+// @Tags			note
+// @Accept			json
+// @Produce		json
+// @Param			q					query	string	true	"Поисковый запрос"
+// @Param			limit				query	int		false	"Максимальное число результатов (1-50, по умолчанию 10)"
+// @Param			include_archived	query	bool	false	"Искать также среди архивных заметок"
+// @Success		200	{object}	response.SemanticSearchResponse	"Найденные заметки с оценками"
+// @Failure		400	{object}	response.ErrorResponse	"Ошибка валидации VALIDATION_ERROR"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка генерации эмбеддинга EMBEDDING_ERROR, ошибка БД DB_ERROR"
+// @Failure		429	{object}	response.ErrorResponse	"Превышен лимит запросов к модели AI_RATE_LIMITED"
+// @Failure		503	{object}	response.ErrorResponse	"Модель недоступна AI_UNAVAILABLE"
+// @Failure		504	{object}	response.ErrorResponse	"Модель не ответила вовремя AI_TIMEOUT"
+// @Router			/notes/search/semantic [get]
+func SemanticSearchHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input SemanticSearchInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	if input.Limit == 0 {
+		input.Limit = 10
+	}
+
+	// 1) Эмбеддинг запроса
+	queryEmb, err := service.GenerateQueryEmbedding(input.Query)
+	if err != nil {
+		c.JSON(aiError(err, "Ошибка генерации эмбеддинга", "EMBEDDING_ERROR"))
+		return
+	}
+
+	// 2) Ранжирование заметок
+	hits, err := search.Semantic(userID, queryEmb, search.SemanticOptions{
+		Limit:           input.Limit,
+		IncludeArchived: input.IncludeArchived,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при поиске заметок",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// 3) Подгружаем найденные заметки целиком
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.NoteID)
+	}
+	notesByID, err := loadNotesByIDs(userID, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении заметок",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	results := make([]response.SemanticSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		note, ok := notesByID[hit.NoteID]
+		if !ok {
+			continue
+		}
+		results = append(results, response.SemanticSearchResult{
+			Note:  noteToResponse(note),
+			Score: hit.Score,
+			Chunk: chunkToResponse(hit.Chunk),
+		})
+	}
+
+	c.JSON(http.StatusOK, response.SemanticSearchResponse{
+		Results: results,
+		Total:   len(results),
+	})
+}
+
+// HybridSearchInput параметры гибридного поиска (query string)
+type HybridSearchInput struct {
+	Query           string   `form:"q" binding:"required"`
+	Limit           int      `form:"limit" binding:"omitempty,min=1,max=50"`
+	IncludeArchived bool     `form:"include_archived"`
+	Method          string   `form:"method" binding:"omitempty,oneof=rrf weighted"`
+	TextWeight      *float64 `form:"text_weight" binding:"omitempty,min=0,max=1"`
+}
+
+// HybridSearchHandler godoc
+// @Security		BearerAuth
+// @Summary		Гибридный поиск по заметкам
+// @Description	Объединяет полнотекстовый ранг и косинусное сходство эмбеддингов (RRF или взвешенная сумма). Для каждой заметки указано, какой сигнал сработал
+// @Tags			note
+// @Accept			json
+// @Produce		json
+// @Param			q					query	string	true	"Поисковый запрос"
+// @Param			limit				query	int		false	"Максимальное число результатов (1-50, по умолчанию 10)"
+// @Param			include_archived	query	bool	false	"Искать также среди архивных заметок"
+// @Param			method				query	string	false	"Способ слияния (по умолчанию из HYBRID_METHOD)"	Enums(rrf, weighted)
+// @Param			text_weight			query	number	false	"Вес полнотекстового сигнала для weighted (0..1)"
+// @Success		200	{object}	response.HybridSearchResponse	"Найденные заметки"
+// @Failure		400	{object}	response.ErrorResponse	"Ошибка валидации VALIDATION_ERROR"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка генерации эмбеддинга EMBEDDING_ERROR, ошибка БД DB_ERROR"
+// @Failure		429	{object}	response.ErrorResponse	"Превышен лимит запросов к модели AI_RATE_LIMITED"
+// @Failure		503	{object}	response.ErrorResponse	"Модель недоступна AI_UNAVAILABLE"
+// @Failure		504	{object}	response.ErrorResponse	"Модель не ответила вовремя AI_TIMEOUT"
+// @Router			/notes/search/hybrid [get]
+func HybridSearchHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input HybridSearchInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	if input.Limit == 0 {
+		input.Limit = 10
+	}
+	opts := search.HybridOptions{
+		Limit:            input.Limit,
+		IncludeArchived:  input.IncludeArchived,
+		Method:           config.HybridMethod,
+		TextWeight:       config.HybridTextWeight,
+		RRFK:             config.HybridRRFK,
+		SemanticMinScore: config.HybridMinSimilarity,
+	}
+	if input.Method != "" {
+		opts.Method = input.Method
+	}
+	if input.TextWeight != nil {
+		opts.TextWeight = *input.TextWeight
+	}
+
+	queryEmb, err := service.GenerateQueryEmbedding(input.Query)
+	if err != nil {
+		c.JSON(aiError(err, "Ошибка генерации эмбеддинга", "EMBEDDING_ERROR"))
+		return
+	}
+
+	hits, err := search.Hybrid(userID, input.Query, queryEmb, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при поиске заметок",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.NoteID)
+	}
+	notesByID, err := loadNotesByIDs(userID, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении заметок",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	results := make([]response.HybridSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		note, ok := notesByID[hit.NoteID]
+		if !ok {
+			continue
+		}
+		results = append(results, response.HybridSearchResult{
+			Note:           noteToResponse(note),
+			Score:          hit.Score,
+			MatchedBy:      hit.MatchedBy,
+			TextRank:       hit.TextRank,
+			SemanticScore:  hit.SemanticScore,
+			TitleHighlight: hit.TitleHighlight,
+			Snippet:        hit.Snippet,
+			Chunk:          chunkToResponse(hit.Chunk),
+		})
+	}
+
+	c.JSON(http.StatusOK, response.HybridSearchResponse{
+		Method:  opts.Method,
+		Results: results,
+		Total:   len(results),
+	})
+}
+
+// chunkToResponse преобразует совпавший фрагмент заметки в ответ API
+func chunkToResponse(chunk *search.ChunkMatch) *response.ChunkMatch {
+	if chunk == nil {
+		return nil
+	}
+	return &response.ChunkMatch{
+		Index: chunk.Index,
+		Start: chunk.Start,
+		End:   chunk.End,
+		Text:  chunk.Text,
+	}
+}
+
+// loadNotesByIDs подгружает заметки пользователя с тегами и вложениями, сохраняя доступ по ID
+func loadNotesByIDs(userID uint, ids []uint) (map[uint]models.Note, error) {
+	notesByID := make(map[uint]models.Note, len(ids))
+	if len(ids) == 0 {
+		return notesByID, nil
+	}
+
+	var notes []models.Note
+	if err := db.DB.Where("user_id = ? AND id IN ?", userID, ids).Preload("Tags").Preload("Attachments").Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	for _, note := range notes {
+		notesByID[note.ID] = note
+	}
+	return notesByID, nil
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/response"
+	"NeuroNest/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// События потока Server-Sent Events
+const (
+	eventDelta = "delta" // Очередной фрагмент сгенерированного текста
+	eventDone  = "done"  // Итоговый сохранённый результат
+	eventError = "error" // Ошибка после начала потока
+)
+
+// deltaEvent данные события delta
+type deltaEvent struct {
+	Text string `json:"text"`
+}
+
+// wantsEventStream — клиент запросил потоковый ответ через Accept: text/event-stream
+func wantsEventStream(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
+func startEventStream(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // отключаем буферизацию в nginx
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+}
+
+func sendEvent(c *gin.Context, name string, data interface{}) {
+	c.SSEvent(name, data)
+	c.Writer.Flush()
+}
+
+// summarizeNoteStream генерирует резюме заметки с потоковой выдачей и сохраняет результат
+func summarizeNoteStream(c *gin.Context, note *models.Note) {
+	startEventStream(c)
+
+	summary, err := service.SummarizeTextStream(c.Request.Context(), note.Content, func(delta string) error {
+		sendEvent(c, eventDelta, deltaEvent{Text: delta})
+		return nil
+	})
+	if err != nil {
+		_, errResp := aiError(err, "Ошибка генерации резюме", "SUMMARY_ERROR")
+		sendEvent(c, eventError, errResp)
+		return
+	}
+
+	if err := saveNoteSummary(note, summary); err != nil {
+		_, errResp := summarySaveError(err)
+		sendEvent(c, eventError, errResp)
+		return
+	}
+
+	sendEvent(c, eventDone, response.SummarizeResponse{Summary: summary})
+}
+
+// chatStream генерирует ответ на вопрос с потоковой выдачей и сохраняет обмен в историю
+func chatStream(c *gin.Context, userID uint, input ChatInput, questionEmb []float64, contextNotes []service.ContextNote, history []service.ChatTurn) {
+	startEventStream(c)
+
+	answer, cited, err := service.AnswerQuestionStream(c.Request.Context(), input.Question, contextNotes, history, func(delta string) error {
+		sendEvent(c, eventDelta, deltaEvent{Text: delta})
+		return nil
+	})
+	if err != nil {
+		_, errResp := aiError(err, "Ошибка генерации ответа", "CHAT_ERROR")
+		sendEvent(c, eventError, errResp)
+		return
+	}
+
+	entry, err := saveChatExchange(userID, input.ConversationID, input.Question, answer, questionEmb, cited)
+	if err != nil {
+		sendEvent(c, eventError, response.ErrorResponse{
+			Message: "Ошибка при сохранении истории чата",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	sendEvent(c, eventDone, chatToResponse(entry, contextNotes))
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/response"
+	"NeuroNest/internal/search"
+	"NeuroNest/internal/service"
+	"NeuroNest/internal/worker"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Источники оценки предложенного тега
+const (
+	tagSourceEmbedding = "embedding" // Сходство с заметками, уже помеченными тегом
+	tagSourceLLM       = "llm"       // Ответ языковой модели
+)
+
+// SuggestTagsInput параметры подбора тегов (query string)
+type SuggestTagsInput struct {
+	Limit  int   `form:"limit,default=5" binding:"min=1,max=20"`
+	UseLLM *bool `form:"use_llm"` // По умолчанию true
+}
+
+// tagSuggestion оценка тега по каждому из доступных сигналов
+type tagSuggestion struct {
+	tagID     *uint
+	name      string
+	embedding *float64
+	llm       *float64
+}
+
+// confidence — среднее по доступным сигналам
+func (s tagSuggestion) confidence() float64 {
+	var sum float64
+	var n int
+	for _, v := range []*float64{s.embedding, s.llm} {
+		if v != nil {
+			sum += *v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+func (s tagSuggestion) toResponse() response.TagSuggestion {
+	resp := response.TagSuggestion{
+		TagID:          s.tagID,
+		Name:           s.name,
+		Confidence:     s.confidence(),
+		IsNew:          s.tagID == nil,
+		EmbeddingScore: s.embedding,
+		LLMScore:       s.llm,
+	}
+	if s.embedding != nil {
+		resp.Sources = append(resp.Sources, tagSourceEmbedding)
+	}
+	if s.llm != nil {
+		resp.Sources = append(resp.Sources, tagSourceLLM)
+	}
+	return resp
+}
+
+// suggestTags подбирает теги для заметки: существующие теги пользователя оцениваются по сходству эмбеддинга
+// заметки с центроидом заметок под этим тегом и по ответу модели, новые названия предлагает только модель.
+// Если модель недоступна, но есть эмбеддинг, подбор идёт только по эмбеддингам
+func suggestTags(note models.Note, useLLM bool, limit int) ([]response.TagSuggestion, error) {
+	// 1) Теги пользователя и теги, которые уже стоят на заметке
+	var tags []models.Tag
+	if err := db.DB.Where("user_id = ?", note.UserID).Order("path").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	var applied []uint
+	if err := db.DB.Table("note_tags").Where("note_id = ?", note.ID).Pluck("tag_id", &applied).Error; err != nil {
+		return nil, err
+	}
+	skip := make(map[uint]bool, len(applied))
+	for _, id := range applied {
+		skip[id] = true
+	}
+
+	byName := make(map[string]*tagSuggestion, len(tags))
+	var candidates []*tagSuggestion
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		id := tag.ID
+		s := &tagSuggestion{tagID: &id, name: tag.Path}
+		byName[strings.ToLower(tag.Path)] = s
+		names = append(names, tag.Path)
+		if !skip[tag.ID] {
+			candidates = append(candidates, s)
+		}
+	}
+
+	// 2) Сходство с заметками под каждым тегом. Эмбеддинг другой модели с центроидами не сравним
+	embeddingUsed := false
+	model, err := service.EmbeddingModel(service.EmbeddingDocument)
+	if err == nil && len(note.Embedding) > 0 && note.EmbeddingModel == model {
+		var emb []float64
+		if err := json.Unmarshal(note.Embedding, &emb); err != nil {
+			return nil, err
+		}
+		scores, err := search.TagSimilarity(note.UserID, note.ID, emb, model)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[uint]*tagSuggestion, len(candidates))
+		for _, s := range candidates {
+			byID[*s.tagID] = s
+		}
+		for _, score := range scores {
+			if s, ok := byID[score.TagID]; ok {
+				v := clampScore(score.Score)
+				s.embedding = &v
+			}
+		}
+		embeddingUsed = true
+	}
+
+	// 3) Ответ модели: существующий тег, который модель не выбрала, получает от неё 0
+	if useLLM {
+		answer, err := service.SuggestTags(note.Title, note.Content, names, limit)
+		if err != nil {
+			if !embeddingUsed {
+				return nil, err
+			}
+		} else {
+			for _, s := range candidates {
+				zero := 0.0
+				s.llm = &zero
+			}
+			for _, c := range answer {
+				v := c.Confidence
+				if s, ok := byName[strings.ToLower(c.Name)]; ok {
+					s.llm = &v
+					continue
+				}
+				s := &tagSuggestion{name: c.Name, llm: &v}
+				byName[strings.ToLower(c.Name)] = s
+				candidates = append(candidates, s)
+			}
+		}
+	}
+
+	// 4) Сортировка по уверенности; теги без единого сигнала не предлагаются
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].confidence() > candidates[j].confidence()
+	})
+	suggestions := make([]response.TagSuggestion, 0, limit)
+	for _, s := range candidates {
+		if len(suggestions) == limit {
+			break
+		}
+		if s.confidence() <= 0 {
+			continue
+		}
+		suggestions = append(suggestions, s.toResponse())
+	}
+	return suggestions, nil
+}
+
+func clampScore(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// SuggestTagsHandler godoc
+// @Security		BearerAuth
+// @Summary		Предложение тегов для заметки
+// @Description	Подбирает теги из существующих тегов пользователя по сходству с заметками, уже помеченными каждым тегом, и по ответу языковой модели. Модель может предложить новые названия (is_new). Уверенность — среднее по доступным источникам. Теги, уже стоящие на заметке, не предлагаются
+// @Tags			tag
+// @Accept			json
+// @Produce		json
+// @Param			id		path		uint	true	"ID заметки"
+// @Param			limit	query		int		false	"Максимум предложений (1-20, по умолчанию 5)"
+// @Param			use_llm	query		bool	false	"Спрашивать языковую модель (по умолчанию true)"
+// @Success		200		{object}	response.TagSuggestionsResponse	"Предложенные теги"
+// @Failure		400		{object}	response.ErrorResponse	"Ошибка валидации VALIDATION_ERROR"
+// @Failure		404		{object}	response.ErrorResponse	"Заметка не найдена NOTE_NOT_FOUND"
+// @Failure		500		{object}	response.ErrorResponse	"Ошибка подбора тегов TAG_SUGGEST_ERROR"
+// @Failure		429		{object}	response.ErrorResponse	"Превышен лимит запросов к модели AI_RATE_LIMITED"
+// @Failure		503		{object}	response.ErrorResponse	"Модель недоступна AI_UNAVAILABLE"
+// @Failure		504		{object}	response.ErrorResponse	"Модель не ответила вовремя AI_TIMEOUT"
+// @Router			/notes/{id}/suggest-tags [get]
+func SuggestTagsHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+	noteID := c.Param("id")
+
+	var input SuggestTagsInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	useLLM := input.UseLLM == nil || *input.UseLLM
+
+	var note models.Note
+	if err := db.DB.Where("id = ? AND user_id = ?", noteID, userID).First(&note).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Заметка не найдена",
+			Code:    "NOTE_NOT_FOUND",
+		})
+		return
+	}
+
+	suggestions, err := suggestTags(note, useLLM, input.Limit)
+	if err != nil {
+		c.JSON(aiError(err, "Ошибка подбора тегов", "TAG_SUGGEST_ERROR"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.TagSuggestionsResponse{
+		NoteID:      note.ID,
+		Suggestions: suggestions,
+		Total:       len(suggestions),
+	})
+}
+
+// autoTagLimit сколько предложений рассматривается при автоматическом проставлении тегов
+const autoTagLimit = 10
+
+// enqueueAutoTag ставит в очередь автоматическое проставление тегов, если пользователь его включил.
+// Для каждой заметки это делается один раз — после первого расчёта эмбеддинга
+func enqueueAutoTag(note models.Note) error {
+	var user models.User
+	if err := db.DB.Select("id", "auto_tag_enabled").Where("id = ?", note.UserID).First(&user).Error; err != nil {
+		return err
+	}
+	if !user.AutoTagEnabled {
+		return nil
+	}
+
+	var count int64
+	if err := db.DB.Unscoped().Model(&models.AIJob{}).
+		Where("note_id = ? AND type = ?", note.ID, models.AIJobAutoTag).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return worker.Enqueue(db.DB, models.AIJobAutoTag, note.ID, note.UserID)
+}
+
+// autoTagNoteJob проставляет заметке существующие теги, уверенность которых не ниже порога пользователя.
+// Новые названия тегов автоматически не создаются
+func autoTagNoteJob(ctx context.Context, job models.AIJob) error {
+	note, err := loadJobNote(job)
+	if err != nil || note == nil {
+		return err
+	}
+
+	var user models.User
+	if err := db.DB.Where("id = ?", note.UserID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.AutoTagEnabled {
+		return nil
+	}
+
+	suggestions, err := suggestTags(*note, true, autoTagLimit)
+	if err != nil {
+		return err
+	}
+
+	var rows []map[string]interface{}
+	for _, s := range suggestions {
+		if s.TagID != nil && s.Confidence >= user.AutoTagThreshold {
+			rows = append(rows, map[string]interface{}{"note_id": note.ID, "tag_id": *s.TagID})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return db.DB.Table("note_tags").Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/response"
+	"errors"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tagPathSeparator разделяет сегменты пути иерархического тега: work/clients/acme
+const tagPathSeparator = "/"
+
+var errTagCycle = errors.New("tag cannot be moved into its own subtree")
+
+// tagDescendantsSQL условие «тег d — это тег t или его потомок» (по путям, без LIKE: в именах бывают % и _)
+const tagDescendantsSQL = "d.user_id = t.user_id AND d.deleted_at IS NULL AND (d.id = t.id OR left(d.path, length(t.path) + 1) = t.path || '/')"
+
+// splitTagPath разбирает путь тега на сегменты без пробелов по краям. ok = false, если есть пустой сегмент
+func splitTagPath(path string) (segments []string, ok bool) {
+	for _, segment := range strings.Split(path, tagPathSeparator) {
+		segment = strings.TrimSpace(segment)
+		if segment == "" {
+			return nil, false
+		}
+		segments = append(segments, segment)
+	}
+	return segments, true
+}
+
+// ensureTagAncestors находит или создаёт теги для каждого префикса пути и возвращает ID последнего.
+// Пустой список сегментов — тег верхнего уровня (nil). Если тот же предок параллельно создаёт
+// другой запрос, вставка пропускается (ON CONFLICT DO NOTHING) и предок перечитывается
+func ensureTagAncestors(tx *gorm.DB, userID uint, segments []string) (*uint, error) {
+	var parentID *uint
+	for i, segment := range segments {
+		path := strings.Join(segments[:i+1], tagPathSeparator)
+		var tag models.Tag
+		err := tx.Where("user_id = ? AND path = ?", userID, path).First(&tag).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			tag = models.Tag{UserID: userID, ParentID: parentID, Name: segment, Path: path}
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag)
+			err = res.Error
+			if err == nil && res.RowsAffected == 0 {
+				tag = models.Tag{}
+				err = tx.Where("user_id = ? AND path = ?", userID, path).First(&tag).Error
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		id := tag.ID
+		parentID = &id
+	}
+	return parentID, nil
+}
+
+// childTagPath путь тега name внутри parent (nil — верхний уровень)
+func childTagPath(parent *models.Tag, name string) string {
+	if parent == nil {
+		return name
+	}
+	return parent.Path + tagPathSeparator + name
+}
+
+// isTagDescendant — тег с путём path лежит внутри тега с путём ancestor
+func isTagDescendant(path, ancestor string) bool {
+	return strings.HasPrefix(path, ancestor+tagPathSeparator)
+}
+
+// rewriteTagSubtree переписывает пути всех потомков тега при его переименовании или переносе:
+// префикс oldPath заменяется на newPath. Пустой newPath поднимает потомков на уровень выше удалённого тега верхнего уровня
+func rewriteTagSubtree(tx *gorm.DB, userID uint, oldPath, newPath string) error {
+	prefix := oldPath + tagPathSeparator
+	n := utf8.RuneCountInString(prefix)
+	q := tx.Model(&models.Tag{}).Where("user_id = ? AND left(path, ?) = ?", userID, n, prefix)
+	if newPath == "" {
+		return q.Update("path", gorm.Expr("substr(path, ?)", n+1)).Error
+	}
+	return q.Update("path", gorm.Expr("? || substr(path, ?)", newPath, n)).Error
+}
+
+// buildTagTree раскладывает теги по родителям. Тег, родитель которого не попал в выборку, становится корнем
+func buildTagTree(tags []models.Tag, usage map[uint]tagUsage) []response.TagResponse {
+	exists := make(map[uint]bool, len(tags))
+	for _, tag := range tags {
+		exists[tag.ID] = true
+	}
+	children := make(map[uint][]models.Tag)
+	var roots []models.Tag
+	for _, tag := range tags {
+		if tag.ParentID == nil || !exists[*tag.ParentID] {
+			roots = append(roots, tag)
+			continue
+		}
+		children[*tag.ParentID] = append(children[*tag.ParentID], tag)
+	}
+
+	var build func(tag models.Tag) response.TagResponse
+	build = func(tag models.Tag) response.TagResponse {
+		node := tagToResponse(tag, usage[tag.ID])
+		for _, child := range children[tag.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	tree := make([]response.TagResponse, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+	return tree
+}
+
+// hoistTagChildren перед удалением тега переносит его детей к его родителю (или на верхний уровень)
+// вместе с поддеревьями. Сам тег должен быть уже удалён, чтобы освободить свой путь
+func hoistTagChildren(tx *gorm.DB, tag models.Tag) error {
+	newPath := ""
+	if tag.ParentID != nil {
+		var parent models.Tag
+		if err := tx.Where("id = ?", *tag.ParentID).First(&parent).Error; err != nil {
+			return err
+		}
+		newPath = parent.Path
+	}
+	if err := tx.Model(&models.Tag{}).Where("parent_id = ?", tag.ID).Update("parent_id", tag.ParentID).Error; err != nil {
+		return err
+	}
+	return rewriteTagSubtree(tx, tag.UserID, tag.Path, newPath)
+}
+
+// mergeTagInto вливает тег source в target: заметки source получают тег target, source удаляется,
+// а его дети переносятся внутрь target вместе с поддеревьями. Ребёнок, одноимённый с ребёнком target,
+// не переносится, а так же вливается в него
+func mergeTagInto(tx *gorm.DB, source, target models.Tag) error {
+	// 1) Заметки, у которых уже есть целевой тег, сохраняют свою связь с ним
+	if err := tx.Exec(`INSERT INTO note_tags (note_id, tag_id, created_at)
+		SELECT note_id, ?, created_at FROM note_tags WHERE tag_id = ?
+		ON CONFLICT DO NOTHING`, target.ID, source.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM note_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+		return err
+	}
+	// 2) source удаляется до переноса детей, чтобы освободить свой путь
+	if err := tx.Delete(&source).Error; err != nil {
+		return err
+	}
+
+	// 3) Дети source
+	var children []models.Tag
+	if err := tx.Where("parent_id = ?", source.ID).Order("id").Find(&children).Error; err != nil {
+		return err
+	}
+	for _, child := range children {
+		newPath := childTagPath(&target, child.Name)
+		var twin models.Tag
+		err := tx.Where("user_id = ? AND path = ?", target.UserID, newPath).First(&twin).Error
+		if err == nil {
+			if err := mergeTagInto(tx, child, twin); err != nil {
+				return err
+			}
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		oldPath := child.Path
+		if err := tx.Model(&models.Tag{}).Where("id = ?", child.ID).
+			Updates(map[string]interface{}{"parent_id": target.ID, "path": newPath}).Error; err != nil {
+			return err
+		}
+		if err := rewriteTagSubtree(tx, child.UserID, oldPath, newPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// legacyTagSlash заменяет «/» в имени старого тега, который не удалось разложить по иерархии
+const legacyTagSlash = "∕"
+
+// SplitLegacyTagPaths раскладывает по иерархии теги, созданные до вложенных тегов, в именах которых есть «/»:
+// недостающие предки создаются, тег получает родителя и последний сегмент как имя.
+// Если путь уже занят другим тегом или в имени есть пустые сегменты, «/» в имени заменяется на «∕»
+func SplitLegacyTagPaths() {
+	var tags []models.Tag
+	if err := db.DB.Where("parent_id IS NULL AND name LIKE ?", "%"+tagPathSeparator+"%").Order("id").Find(&tags).Error; err != nil {
+		log.Printf("Ошибка поиска тегов со «/» в имени: %v", err)
+		return
+	}
+
+	for _, tag := range tags {
+		err := db.DB.Transaction(func(tx *gorm.DB) error {
+			segments, ok := splitTagPath(tag.Name)
+			if !ok {
+				return errors.New("empty tag path segment")
+			}
+			parentID, err := ensureTagAncestors(tx, tag.UserID, segments[:len(segments)-1])
+			if err != nil {
+				return err
+			}
+			return tx.Model(&models.Tag{}).Where("id = ?", tag.ID).Updates(map[string]interface{}{
+				"name":      segments[len(segments)-1],
+				"path":      strings.Join(segments, tagPathSeparator),
+				"parent_id": parentID,
+			}).Error
+		})
+		if err == nil {
+			continue
+		}
+
+		log.Printf("Тег %d «%s» не разложен по иерархии (%v), «/» в имени заменён", tag.ID, tag.Name, err)
+		escaped := strings.ReplaceAll(tag.Name, tagPathSeparator, legacyTagSlash)
+		if err := db.DB.Model(&models.Tag{}).Where("id = ?", tag.ID).
+			Updates(map[string]interface{}{"name": escaped, "path": escaped}).Error; err != nil {
+			log.Printf("Ошибка переименования тега %d: %v", tag.ID, err)
+		}
+	}
+	if len(tags) > 0 {
+		log.Printf("Обработано тегов со «/» в имени: %d", len(tags))
+	}
+}
@@ -4,26 +4,36 @@ import (
 	"NeuroNest/internal/db"
 	"NeuroNest/internal/models"
 	"NeuroNest/internal/response"
+	"errors"
 	"net/http"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type TagInput struct {
-	Name        string `json:"name" binding:"required"`
+	Name        string `json:"name" binding:"required"` // Имя или путь через «/»: недостающие родительские теги создаются
 	Description string `json:"description"`
+	Color       string `json:"color"` // #RRGGBB
+	Icon        string `json:"icon"`
+	GetOrCreate bool   `json:"get_or_create"` // Вернуть существующий тег с таким путём вместо ошибки TAG_EXISTS
 }
 
 // CreateTagsHandler godoc
 // @Security		BearerAuth
 // @Summary		Создать тег
-// @Description	Создаёт новый тег
+// @Description	Создаёт новый тег. Имя вида work/clients/acme создаёт вложенный тег вместе с недостающими родителями. Пути тегов уникальны в пределах пользователя: при совпадении возвращается TAG_EXISTS с ID существующего тега, а с get_or_create=true — сам существующий тег со статусом 200
 // @Tags			tag
 // @Accept		json
 // @Produce		json
-// @Param			tag body TagInput true "Данные заметки"
-// @Success		201	{object}	response.SuccessResponse	"Тег успешно создан"
+// @Param			tag body TagInput true "Данные тега"
+// @Success		201	{object}	response.TagCreatedResponse	"Тег успешно создан"
+// @Success		200	{object}	response.TagCreatedResponse	"Тег уже существует (get_or_create)"
 // @Failure		400	{object}	response.ErrorResponse	"Ошибка валидации"
+// @Failure		409	{object}	response.TagExistsResponse	"Тег с таким именем уже существует TAG_EXISTS"
 // @Failure		500	{object}	response.ErrorResponse	"Ошибка при создании тега"
 // @Router			/tags/create [post]
 func CreateTagsHandler(c *gin.Context) {
@@ -37,14 +47,59 @@ func CreateTagsHandler(c *gin.Context) {
 		})
 		return
 	}
+	segments, ok := splitTagPath(input.Name)
+	if !ok {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:    "VALIDATION_ERROR",
+			Message: "Имя тега и сегменты его пути не могут быть пустыми",
+		})
+		return
+	}
+	if !validTagColor(input.Color) {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:    "VALIDATION_ERROR",
+			Message: "Цвет тега должен быть в формате #RRGGBB",
+		})
+		return
+	}
 
 	tag := models.Tag{
 		UserID:      userID,
-		Name:        input.Name,
+		Name:        segments[len(segments)-1],
+		Path:        strings.Join(segments, tagPathSeparator),
 		Description: input.Description,
+		Color:       input.Color,
+		Icon:        input.Icon,
 	}
 
-	if err := db.DB.Create(&tag).Error; err != nil {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		parentID, err := ensureTagAncestors(tx, userID, segments[:len(segments)-1])
+		if err != nil {
+			return err
+		}
+		tag.ParentID = parentID
+		return tx.Create(&tag).Error
+	})
+	if err != nil && db.IsUniqueViolation(err) {
+		// Тег с таким путём у пользователя уже есть
+		var existing models.Tag
+		if findErr := db.DB.Where("user_id = ? AND path = ?", userID, tag.Path).First(&existing).Error; findErr == nil {
+			if input.GetOrCreate {
+				c.JSON(http.StatusOK, response.TagCreatedResponse{
+					Message: "Тег уже существует",
+					Tag:     tagToResponse(existing, tagUsage{}),
+				})
+				return
+			}
+			c.JSON(http.StatusConflict, response.TagExistsResponse{
+				Message: "Тег с таким именем уже существует",
+				Code:    "TAG_EXISTS",
+				TagID:   existing.ID,
+			})
+			return
+		}
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
 			Message: "Ошибка при создании тега",
 			Code:    "DB_ERROR",
@@ -52,25 +107,80 @@ func CreateTagsHandler(c *gin.Context) {
 		})
 		return
 	}
-	c.JSON(http.StatusCreated, response.SuccessResponse{
+	c.JSON(http.StatusCreated, response.TagCreatedResponse{
 		Message: "Тег успешно создан",
+		Tag:     tagToResponse(tag, tagUsage{}),
 	})
 }
 
+var tagColorRe = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// validTagColor — пустой цвет (без цвета) или #RRGGBB
+func validTagColor(color string) bool {
+	return color == "" || tagColorRe.MatchString(color)
+}
+
+// tagUsage сколько неудалённых заметок помечено тегом и когда тег ставили последний раз
+type tagUsage struct {
+	TagID      uint
+	NoteCount  int64
+	LastUsedAt *time.Time
+}
+
+// loadTagUsage считает использование тегов по note_tags
+func loadTagUsage(tagIDs []uint) (map[uint]tagUsage, error) {
+	usage := make(map[uint]tagUsage, len(tagIDs))
+	if len(tagIDs) == 0 {
+		return usage, nil
+	}
+	var rows []tagUsage
+	if err := db.DB.Table("note_tags").
+		Select("note_tags.tag_id, COUNT(*) AS note_count, MAX(note_tags.created_at) AS last_used_at").
+		Joins("JOIN notes ON notes.id = note_tags.note_id AND notes.deleted_at IS NULL").
+		Where("note_tags.tag_id IN ?", tagIDs).
+		Group("note_tags.tag_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		usage[row.TagID] = row
+	}
+	return usage, nil
+}
+
+func tagToResponse(tag models.Tag, usage tagUsage) response.TagResponse {
+	resp := response.TagResponse{
+		ID:          tag.ID,
+		Name:        tag.Name,
+		Path:        tag.Path,
+		ParentID:    tag.ParentID,
+		Description: tag.Description,
+		Color:       tag.Color,
+		Icon:        tag.Icon,
+		NoteCount:   usage.NoteCount,
+	}
+	if usage.LastUsedAt != nil {
+		lastUsed := usage.LastUsedAt.Format("2006-01-02 15:04:05")
+		resp.LastUsedAt = &lastUsed
+	}
+	return resp
+}
+
 // GetTagsHandler godoc
 // @Security		BearerAuth
 // @Summary		Получить теги
-// @Description	Возвращает список тегов пользователя
+// @Description	Возвращает список тегов пользователя с числом заметок и временем последнего использования. С tree=true в tags лежат теги верхнего уровня, вложенные — в children
 // @Tags			tag
 // @Accept		json
 // @Produce		json
-// @Success		200	{array}	response.TagsListResponse	"Список тегов пользователя"
+// @Param			tree	query	bool	false	"Вернуть теги деревом"
+// @Success		200	{object}	response.TagsListResponse	"Список тегов пользователя"
 // @Failure		500	{object}	response.ErrorResponse	"Ошибка при получении тегов"
 // @Router			/tags/list [get]
 func GetTagsHandler(c *gin.Context) {
 	userID := c.GetUint("userID")
 	var tags []models.Tag
-	if err := db.DB.Where("user_id = ?", userID).Find(&tags).Error; err != nil {
+	if err := db.DB.Where("user_id = ?", userID).Order("path").Find(&tags).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
 			Message: "Ошибка при получении тегов",
 			Code:    "DB_ERROR",
@@ -79,13 +189,31 @@ func GetTagsHandler(c *gin.Context) {
 		return
 	}
 
-	var tagsResponse []response.TagResponse
+	tagIDs := make([]uint, 0, len(tags))
 	for _, tag := range tags {
-		tagsResponse = append(tagsResponse, response.TagResponse{
-			ID:          tag.ID,
-			Name:        tag.Name,
-			Description: tag.Description,
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	usage, err := loadTagUsage(tagIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении тегов",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
 		})
+		return
+	}
+
+	if c.Query("tree") == "true" {
+		c.JSON(http.StatusOK, response.TagsListResponse{
+			Tags:  buildTagTree(tags, usage),
+			Total: len(tags),
+		})
+		return
+	}
+
+	tagsResponse := make([]response.TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		tagsResponse = append(tagsResponse, tagToResponse(tag, usage[tag.ID]))
 	}
 
 	c.JSON(http.StatusOK, response.TagsListResponse{
@@ -104,6 +232,7 @@ func GetTagsHandler(c *gin.Context) {
 // @Param			id	path		uint	true	"ID тега"
 // @Success 200 {object} response.TagResponse "Полученный тег"
 // @Failure 404 {object} response.ErrorResponse "Тег не найден TAG_NOT_FOUND"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при получении тега DB_ERROR"
 // @Router	/tags/{id} [get]
 func GetTagHandler(c *gin.Context) {
 	userID := c.GetUint("userID")
@@ -118,25 +247,30 @@ func GetTagHandler(c *gin.Context) {
 		return
 	}
 
-	tagResp := response.TagResponse{
-		ID:          tag.ID,
-		Name:        tag.Name,
-		Description: tag.Description,
+	usage, err := loadTagUsage([]uint{tag.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении тега",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
 	}
 
-	c.JSON(http.StatusOK, tagResp)
+	c.JSON(http.StatusOK, tagToResponse(tag, usage[tag.ID]))
 }
 
 // DeleteTagHandler godoc
 // @Security		BearerAuth
 // @Summary		Удаление тега
-// @Description	Удаляет тег пользователя по id
+// @Description	Удаляет тег пользователя по id. Вложенные теги переходят к родителю удалённого тега
 // @Tags tag
 // @Accept json
 // @Produce json
 // @Param			id	path		uint	true	"ID тега"
 // @Success 200 {object} response.SuccessResponse "Тег успешно удалён"
 // @Failure 404 {object} response.ErrorResponse "Тег не найден TAG_NOT_FOUND"
+// @Failure 409 {object} response.ErrorResponse "Путь вложенного тега совпал с существующим TAG_EXISTS"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при удалении тега DB_ERROR"
 // @Router	/tags/{id} [delete]
 func DeleteTagHandler(c *gin.Context) {
@@ -182,6 +316,12 @@ func DeleteTagHandler(c *gin.Context) {
 		return
 	}
 
+	if err := hoistTagChildren(tx, tag); err != nil {
+		tx.Rollback()
+		c.JSON(tagSubtreeError(err))
+		return
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
 			Message: "Ошибка при фиксации транзакции",
@@ -195,3 +335,354 @@ func DeleteTagHandler(c *gin.Context) {
 		Message: "Тег успешно удалён",
 	})
 }
+
+// UpdateTagInput частичное обновление тега
+type UpdateTagInput struct {
+	Name        *string `json:"name,omitempty"`      // Имя без «/»: для переноса используется parent_id
+	ParentID    *uint   `json:"parent_id,omitempty"` // 0 — перенести на верхний уровень
+	Description *string `json:"description,omitempty"`
+	Color       *string `json:"color,omitempty"` // #RRGGBB, пустая строка убирает цвет
+	Icon        *string `json:"icon,omitempty"`
+}
+
+// UpdateTagHandler godoc
+// @Security		BearerAuth
+// @Summary		Изменение тега
+// @Description	Переименовывает тег, переносит его к другому родителю, меняет описание, цвет или иконку.
+// @Description	Пути вложенных тегов обновляются вместе с ним
+// @Tags			tag
+// @Accept			json
+// @Produce		json
+// @Param			id	path		uint			true	"ID тега"
+// @Param			tag	body		UpdateTagInput	true	"Изменяемые поля"
+// @Success		200	{object}	response.TagResponse	"Обновлённый тег"
+// @Failure		400	{object}	response.ErrorResponse	"Ошибка валидации VALIDATION_ERROR, перенос тега внутрь себя TAG_CYCLE"
+// @Failure		404	{object}	response.ErrorResponse	"Тег или родитель не найден TAG_NOT_FOUND"
+// @Failure		409	{object}	response.TagExistsResponse	"Тег с таким путём уже существует TAG_EXISTS"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка при обновлении тега DB_ERROR"
+// @Router			/tags/{id} [patch]
+func UpdateTagHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input UpdateTagInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	if input.Name != nil && strings.TrimSpace(*input.Name) == "" {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Имя тега не может быть пустым",
+			Code:    "VALIDATION_ERROR",
+		})
+		return
+	}
+	if input.Name != nil && strings.Contains(*input.Name, tagPathSeparator) {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Имя тега не может содержать «/», для переноса укажите parent_id",
+			Code:    "VALIDATION_ERROR",
+		})
+		return
+	}
+	if input.Color != nil && !validTagColor(*input.Color) {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Цвет тега должен быть в формате #RRGGBB",
+			Code:    "VALIDATION_ERROR",
+		})
+		return
+	}
+
+	var tag models.Tag
+	if err := db.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&tag).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Тег не найден",
+			Code:    "TAG_NOT_FOUND",
+		})
+		return
+	}
+
+	// 1) Новое место тега в иерархии
+	name := tag.Name
+	if input.Name != nil {
+		name = strings.TrimSpace(*input.Name)
+	}
+	parentID := tag.ParentID
+	if input.ParentID != nil {
+		parentID = input.ParentID
+		if *input.ParentID == 0 {
+			parentID = nil
+		}
+	}
+
+	var parent *models.Tag
+	if parentID != nil {
+		parent = &models.Tag{}
+		if err := db.DB.Where("id = ? AND user_id = ?", *parentID, userID).First(parent).Error; err != nil {
+			c.JSON(http.StatusNotFound, response.ErrorResponse{
+				Message: "Родительский тег не найден",
+				Code:    "TAG_NOT_FOUND",
+				Details: "parent_id",
+			})
+			return
+		}
+		if parent.ID == tag.ID || isTagDescendant(parent.Path, tag.Path) {
+			c.JSON(tagSubtreeError(errTagCycle))
+			return
+		}
+	}
+	oldPath := tag.Path
+	newPath := childTagPath(parent, name)
+
+	// 2) Изменяемые поля
+	updates := map[string]interface{}{}
+	if name != tag.Name {
+		updates["name"] = name
+	}
+	if newPath != oldPath {
+		updates["path"] = newPath
+	}
+	if input.ParentID != nil {
+		updates["parent_id"] = parentID
+	}
+	if input.Description != nil {
+		updates["description"] = *input.Description
+	}
+	if input.Color != nil {
+		updates["color"] = *input.Color
+	}
+	if input.Icon != nil {
+		updates["icon"] = *input.Icon
+	}
+
+	// 3) Тег и пути его потомков меняются вместе
+	if len(updates) > 0 {
+		err := db.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&tag).Updates(updates).Error; err != nil {
+				return err
+			}
+			if newPath == oldPath {
+				return nil
+			}
+			return rewriteTagSubtree(tx, userID, oldPath, newPath)
+		})
+		if err != nil {
+			if db.IsUniqueViolation(err) {
+				var existing models.Tag
+				if findErr := db.DB.Where("user_id = ? AND path = ?", userID, newPath).First(&existing).Error; findErr == nil {
+					c.JSON(http.StatusConflict, response.TagExistsResponse{
+						Message: "Тег с таким путём уже существует",
+						Code:    "TAG_EXISTS",
+						TagID:   existing.ID,
+					})
+					return
+				}
+			}
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+				Message: "Ошибка при обновлении тега",
+				Code:    "DB_ERROR",
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+
+	usage, err := loadTagUsage([]uint{tag.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении тега",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, tagToResponse(tag, usage[tag.ID]))
+}
+
+// MergeTagInput тег, в который вливается тег из пути
+type MergeTagInput struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
+
+// MergeTagHandler godoc
+// @Security		BearerAuth
+// @Summary		Слияние тегов
+// @Description	Переносит все заметки и вложенные теги тега из пути на тег target_id и удаляет исходный тег.
+// @Description	Вложенный тег, одноимённый с вложенным тегом target_id, сливается с ним так же рекурсивно
+// @Tags			tag
+// @Accept			json
+// @Produce		json
+// @Param			id		path		uint			true	"ID вливаемого тега"
+// @Param			input	body		MergeTagInput	true	"Тег, в который вливается исходный"
+// @Success		200		{object}	response.TagResponse	"Тег после слияния"
+// @Failure		400		{object}	response.ErrorResponse	"Ошибка валидации VALIDATION_ERROR, слияние с собственным потомком TAG_CYCLE"
+// @Failure		404		{object}	response.ErrorResponse	"Тег не найден TAG_NOT_FOUND"
+// @Failure		500		{object}	response.ErrorResponse	"Ошибка при слиянии тегов DB_ERROR"
+// @Router			/tags/{id}/merge [post]
+func MergeTagHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input MergeTagInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var source, target models.Tag
+	if err := db.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&source).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Тег не найден",
+			Code:    "TAG_NOT_FOUND",
+		})
+		return
+	}
+	if source.ID == input.TargetID {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Нельзя слить тег сам с собой",
+			Code:    "VALIDATION_ERROR",
+		})
+		return
+	}
+	if err := db.DB.Where("id = ? AND user_id = ?", input.TargetID, userID).First(&target).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Тег не найден",
+			Code:    "TAG_NOT_FOUND",
+			Details: "target_id",
+		})
+		return
+	}
+	if isTagDescendant(target.Path, source.Path) {
+		c.JSON(tagSubtreeError(errTagCycle))
+		return
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		return mergeTagInto(tx, source, target)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при слиянии тегов",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	usage, err := loadTagUsage([]uint{target.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении тега",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, tagToResponse(target, usage[target.ID]))
+}
+
+// AddNoteTagHandler godoc
+// @Security		BearerAuth
+// @Summary		Добавление тега заметке
+// @Description	Ставит заметке тег пользователя. Повторная постановка того же тега ничего не меняет
+// @Tags			tag
+// @Accept			json
+// @Produce		json
+// @Param			id		path		uint	true	"ID заметки"
+// @Param			tagId	path		uint	true	"ID тега"
+// @Success		200		{object}	response.NoteResponse	"Заметка с обновлёнными тегами"
+// @Failure		404		{object}	response.ErrorResponse	"Заметка не найдена NOTE_NOT_FOUND, тег не найден TAG_NOT_FOUND"
+// @Failure		500		{object}	response.ErrorResponse	"Ошибка БД DB_ERROR"
+// @Router			/notes/{id}/tags/{tagId} [post]
+func AddNoteTagHandler(c *gin.Context) {
+	changeNoteTag(c, func(noteID, tagID uint) error {
+		return db.DB.Exec("INSERT INTO note_tags (note_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", noteID, tagID).Error
+	})
+}
+
+// RemoveNoteTagHandler godoc
+// @Security		BearerAuth
+// @Summary		Снятие тега с заметки
+// @Description	Убирает тег с заметки. Если тега на заметке нет, ничего не меняет
+// @Tags			tag
+// @Accept			json
+// @Produce		json
+// @Param			id		path		uint	true	"ID заметки"
+// @Param			tagId	path		uint	true	"ID тега"
+// @Success		200		{object}	response.NoteResponse	"Заметка с обновлёнными тегами"
+// @Failure		404		{object}	response.ErrorResponse	"Заметка не найдена NOTE_NOT_FOUND, тег не найден TAG_NOT_FOUND"
+// @Failure		500		{object}	response.ErrorResponse	"Ошибка БД DB_ERROR"
+// @Router			/notes/{id}/tags/{tagId} [delete]
+func RemoveNoteTagHandler(c *gin.Context) {
+	changeNoteTag(c, func(noteID, tagID uint) error {
+		return db.DB.Exec("DELETE FROM note_tags WHERE note_id = ? AND tag_id = ?", noteID, tagID).Error
+	})
+}
+
+// changeNoteTag проверяет, что заметка и тег принадлежат пользователю, применяет change и возвращает заметку
+func changeNoteTag(c *gin.Context, change func(noteID, tagID uint) error) {
+	userID := c.GetUint("userID")
+
+	var note models.Note
+	if err := db.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&note).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Заметка не найдена",
+			Code:    "NOTE_NOT_FOUND",
+		})
+		return
+	}
+	var tag models.Tag
+	if err := db.DB.Where("id = ? AND user_id = ?", c.Param("tagId"), userID).First(&tag).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Тег не найден",
+			Code:    "TAG_NOT_FOUND",
+		})
+		return
+	}
+
+	if err := change(note.ID, tag.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при изменении тегов заметки",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := db.DB.Where("id = ?", note.ID).Preload("Tags").Preload("Attachments").First(&note).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении заметки",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, noteToResponse(note))
+}
+
+// tagSubtreeError ответ на ошибку при переносе вложенных тегов
+func tagSubtreeError(err error) (int, response.ErrorResponse) {
+	switch {
+	case errors.Is(err, errTagCycle):
+		return http.StatusBadRequest, response.ErrorResponse{
+			Message: "Нельзя перенести тег внутрь него самого",
+			Code:    "TAG_CYCLE",
+		}
+	case db.IsUniqueViolation(err):
+		return http.StatusConflict, response.ErrorResponse{
+			Message: "Путь одного из вложенных тегов совпадает с существующим тегом",
+			Code:    "TAG_EXISTS",
+			Details: err.Error(),
+		}
+	}
+	return http.StatusInternalServerError, response.ErrorResponse{
+		Message: "Ошибка при переносе вложенных тегов",
+		Code:    "DB_ERROR",
+		Details: err.Error(),
+	}
+}
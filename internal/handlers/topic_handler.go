@@ -0,0 +1,537 @@
+package handlers
+
+import (
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/response"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// topicSubtreeSQL выбирает ID темы и всех вложенных в неё тем.
+// UNION вместо UNION ALL: даже если в данных окажется цикл, рекурсия остановится на уже выбранных темах
+const topicSubtreeSQL = `WITH RECURSIVE subtree AS (
+		SELECT id FROM topics WHERE id = ? AND deleted_at IS NULL
+		UNION
+		SELECT topics.id FROM topics JOIN subtree ON topics.parent_id = subtree.id WHERE topics.deleted_at IS NULL
+	) SELECT id FROM subtree`
+
+var (
+	errTopicNotFound = errors.New("topic not found")
+	errTopicExists   = errors.New("topic already exists")
+	errTopicCycle    = errors.New("topic cannot be moved into its own subtree")
+)
+
+type TopicInput struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	ParentID    *uint  `json:"parent_id"` // Родительская тема; не указана — тема верхнего уровня
+}
+
+// UpdateTopicInput частичное обновление темы. parent_id = 0 переносит тему на верхний уровень
+type UpdateTopicInput struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	ParentID    *uint   `json:"parent_id,omitempty"`
+}
+
+// MoveNoteTopicInput тема, в которую переносится заметка; 0 убирает заметку из темы
+type MoveNoteTopicInput struct {
+	TopicID uint `json:"topic_id"`
+}
+
+func topicToResponse(topic models.Topic) response.TopicResponse {
+	return response.TopicResponse{
+		ID:          topic.ID,
+		Name:        topic.Name,
+		Description: topic.Description,
+		ParentID:    topic.ParentID,
+		CreatedAt:   topic.CreatedAt.Format("2006-01-02"),
+	}
+}
+
+// checkTopicParent проверяет, что родительская тема принадлежит пользователю
+// и что среди её детей нет темы с таким же именем (кроме самой темы exceptID)
+func checkTopicParent(tx *gorm.DB, userID uint, parentID *uint, name string, exceptID uint) error {
+	if parentID != nil {
+		var count int64
+		if err := tx.Model(&models.Topic{}).Where("id = ? AND user_id = ?", *parentID, userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errTopicNotFound
+		}
+	}
+
+	q := tx.Model(&models.Topic{}).Where("user_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", userID, name, exceptID)
+	if parentID != nil {
+		q = q.Where("parent_id = ?", *parentID)
+	} else {
+		q = q.Where("parent_id IS NULL")
+	}
+	var count int64
+	if err := q.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errTopicExists
+	}
+	return nil
+}
+
+// topicErrorResponse ответ на ошибку проверки темы
+func topicErrorResponse(err error) (int, response.ErrorResponse) {
+	switch {
+	case errors.Is(err, errTopicNotFound):
+		return http.StatusNotFound, response.ErrorResponse{
+			Message: "Тема не найдена",
+			Code:    "TOPIC_NOT_FOUND",
+		}
+	case errors.Is(err, errTopicExists):
+		return http.StatusConflict, response.ErrorResponse{
+			Message: "Тема с таким названием уже есть на этом уровне",
+			Code:    "TOPIC_EXISTS",
+		}
+	case errors.Is(err, errTopicCycle):
+		return http.StatusBadRequest, response.ErrorResponse{
+			Message: "Нельзя перенести тему внутрь неё самой",
+			Code:    "TOPIC_CYCLE",
+		}
+	}
+	return http.StatusInternalServerError, response.ErrorResponse{
+		Message: "Ошибка при сохранении темы",
+		Code:    "DB_ERROR",
+		Details: err.Error(),
+	}
+}
+
+// CreateTopicHandler godoc
+// @Security		BearerAuth
+// @Summary		Создать тему
+// @Description	Создаёт тему (блокнот) пользователя, при указании parent_id — вложенную
+// @Tags			topic
+// @Accept			json
+// @Produce		json
+// @Param			topic	body		TopicInput	true	"Данные темы"
+// @Success		201		{object}	response.TopicResponse	"Созданная тема"
+// @Failure		400		{object}	response.ErrorResponse	"Ошибка валидации VALIDATION_ERROR"
+// @Failure		404		{object}	response.ErrorResponse	"Родительская тема не найдена TOPIC_NOT_FOUND"
+// @Failure		409		{object}	response.ErrorResponse	"Тема с таким названием уже есть TOPIC_EXISTS"
+// @Failure		500		{object}	response.ErrorResponse	"Ошибка при создании темы DB_ERROR"
+// @Router			/topics/create [post]
+func CreateTopicHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input TopicInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Название темы не может быть пустым",
+			Code:    "VALIDATION_ERROR",
+		})
+		return
+	}
+
+	topic := models.Topic{
+		UserID:      userID,
+		ParentID:    input.ParentID,
+		Name:        input.Name,
+		Description: input.Description,
+	}
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkTopicParent(tx, userID, topic.ParentID, topic.Name, 0); err != nil {
+			return err
+		}
+		return tx.Create(&topic).Error
+	})
+	if err != nil {
+		c.JSON(topicErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, topicToResponse(topic))
+}
+
+// GetTopicsHandler godoc
+// @Security		BearerAuth
+// @Summary		Список тем
+// @Description	Возвращает все темы пользователя плоским списком
+// @Tags			topic
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	response.TopicsListResponse	"Темы пользователя"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка при получении тем DB_ERROR"
+// @Router			/topics/list [get]
+func GetTopicsHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var topics []models.Topic
+	if err := db.DB.Where("user_id = ?", userID).Order("name").Find(&topics).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении тем",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	topicsResp := make([]response.TopicResponse, 0, len(topics))
+	for _, topic := range topics {
+		topicsResp = append(topicsResp, topicToResponse(topic))
+	}
+	c.JSON(http.StatusOK, response.TopicsListResponse{
+		Topics: topicsResp,
+		Total:  len(topicsResp),
+	})
+}
+
+// GetTopicTreeHandler godoc
+// @Security		BearerAuth
+// @Summary		Дерево тем
+// @Description	Возвращает темы пользователя деревом. note_count — заметки непосредственно в теме, total_note_count — вместе с вложенными темами. Заметки в корзине не считаются
+// @Tags			topic
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	response.TopicTreeResponse	"Дерево тем"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка при получении тем DB_ERROR"
+// @Router			/topics/tree [get]
+func GetTopicTreeHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	tree, unsorted, err := topicTree(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении тем",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.TopicTreeResponse{
+		Topics:        tree,
+		UnsortedCount: unsorted,
+	})
+}
+
+// topicTree строит дерево тем пользователя с числом заметок
+func topicTree(userID uint) ([]response.TopicTreeNode, int64, error) {
+	var topics []models.Topic
+	if err := db.DB.Where("user_id = ?", userID).Order("name").Find(&topics).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var counts []struct {
+		TopicID *uint
+		Count   int64
+	}
+	if err := db.DB.Model(&models.Note{}).
+		Select("topic_id, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("topic_id").
+		Scan(&counts).Error; err != nil {
+		return nil, 0, err
+	}
+	direct := make(map[uint]int64, len(counts))
+	var unsorted int64
+	for _, row := range counts {
+		if row.TopicID == nil {
+			unsorted = row.Count
+			continue
+		}
+		direct[*row.TopicID] = row.Count
+	}
+
+	children := make(map[uint][]models.Topic)
+	exists := make(map[uint]bool, len(topics))
+	for _, topic := range topics {
+		exists[topic.ID] = true
+	}
+	var roots []models.Topic
+	for _, topic := range topics {
+		// Тема с удалённым родителем показывается на верхнем уровне
+		if topic.ParentID == nil || !exists[*topic.ParentID] {
+			roots = append(roots, topic)
+			continue
+		}
+		children[*topic.ParentID] = append(children[*topic.ParentID], topic)
+	}
+
+	var build func(topic models.Topic) response.TopicTreeNode
+	build = func(topic models.Topic) response.TopicTreeNode {
+		node := response.TopicTreeNode{
+			ID:             topic.ID,
+			Name:           topic.Name,
+			Description:    topic.Description,
+			NoteCount:      direct[topic.ID],
+			TotalNoteCount: direct[topic.ID],
+			Children:       []response.TopicTreeNode{},
+		}
+		for _, child := range children[topic.ID] {
+			childNode := build(child)
+			node.TotalNoteCount += childNode.TotalNoteCount
+			node.Children = append(node.Children, childNode)
+		}
+		return node
+	}
+
+	tree := make([]response.TopicTreeNode, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+	return tree, unsorted, nil
+}
+
+// GetTopicHandler godoc
+// @Security		BearerAuth
+// @Summary		Получение темы
+// @Description	Возвращает тему пользователя по id
+// @Tags			topic
+// @Accept			json
+// @Produce		json
+// @Param			id	path		uint	true	"ID темы"
+// @Success		200	{object}	response.TopicResponse	"Тема"
+// @Failure		404	{object}	response.ErrorResponse	"Тема не найдена TOPIC_NOT_FOUND"
+// @Router			/topics/{id} [get]
+func GetTopicHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var topic models.Topic
+	if err := db.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&topic).Error; err != nil {
+		c.JSON(topicErrorResponse(errTopicNotFound))
+		return
+	}
+	c.JSON(http.StatusOK, topicToResponse(topic))
+}
+
+// UpdateTopicHandler godoc
+// @Security		BearerAuth
+// @Summary		Изменение темы
+// @Description	Переименовывает тему, меняет описание или переносит её вместе с вложенными темами и заметками под другую тему (parent_id = 0 — на верхний уровень)
+// @Tags			topic
+// @Accept			json
+// @Produce		json
+// @Param			id		path		uint				true	"ID темы"
+// @Param			topic	body		UpdateTopicInput	true	"Изменяемые поля"
+// @Success		200		{object}	response.TopicResponse	"Обновлённая тема"
+// @Failure		400		{object}	response.ErrorResponse	"Ошибка валидации VALIDATION_ERROR, перенос внутрь себя TOPIC_CYCLE"
+// @Failure		404		{object}	response.ErrorResponse	"Тема не найдена TOPIC_NOT_FOUND"
+// @Failure		409		{object}	response.ErrorResponse	"Тема с таким названием уже есть TOPIC_EXISTS"
+// @Failure		500		{object}	response.ErrorResponse	"Ошибка при сохранении темы DB_ERROR"
+// @Router			/topics/{id} [patch]
+func UpdateTopicHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input UpdateTopicInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	if input.Name != nil && strings.TrimSpace(*input.Name) == "" {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Название темы не может быть пустым",
+			Code:    "VALIDATION_ERROR",
+		})
+		return
+	}
+
+	var topic models.Topic
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		// Темы пользователя блокируются до конца транзакции: иначе два встречных переноса
+		// (A в B и B в A) оба пройдут проверку на цикл
+		if err := lockUserTopics(tx, userID); err != nil {
+			return err
+		}
+		if err := tx.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&topic).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errTopicNotFound
+			}
+			return err
+		}
+
+		if input.Name != nil {
+			topic.Name = strings.TrimSpace(*input.Name)
+		}
+		if input.Description != nil {
+			topic.Description = *input.Description
+		}
+		if input.ParentID != nil {
+			if *input.ParentID == 0 {
+				topic.ParentID = nil
+			} else {
+				// Новый родитель не может лежать в поддереве самой темы
+				var subtree []uint
+				if err := tx.Raw(topicSubtreeSQL, topic.ID).Scan(&subtree).Error; err != nil {
+					return err
+				}
+				for _, id := range subtree {
+					if id == *input.ParentID {
+						return errTopicCycle
+					}
+				}
+				parentID := *input.ParentID
+				topic.ParentID = &parentID
+			}
+		}
+		if err := checkTopicParent(tx, userID, topic.ParentID, topic.Name, topic.ID); err != nil {
+			return err
+		}
+		return tx.Model(&topic).Select("name", "description", "parent_id").Updates(&topic).Error
+	})
+	if err != nil {
+		c.JSON(topicErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, topicToResponse(topic))
+}
+
+// DeleteTopicHandler godoc
+// @Security		BearerAuth
+// @Summary		Удаление темы
+// @Description	Удаляет тему. Вложенные темы и заметки (в том числе из корзины) переходят к родителю удалённой темы
+// @Tags			topic
+// @Accept			json
+// @Produce		json
+// @Param			id	path		uint	true	"ID темы"
+// @Success		200	{object}	response.SuccessResponse	"Тема удалена"
+// @Failure		404	{object}	response.ErrorResponse	"Тема не найдена TOPIC_NOT_FOUND"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка при удалении темы DB_ERROR"
+// @Router			/topics/{id} [delete]
+func DeleteTopicHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var topic models.Topic
+	if err := db.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&topic).Error; err != nil {
+		c.JSON(topicErrorResponse(errTopicNotFound))
+		return
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		// Родитель перечитывается под блокировкой: тему могли перенести параллельно
+		if err := lockUserTopics(tx, userID); err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", topic.ID).First(&topic).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Topic{}).Where("parent_id = ?", topic.ID).Update("parent_id", topic.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Note{}).Where("topic_id = ?", topic.ID).UpdateColumn("topic_id", topic.ParentID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&topic).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при удалении темы",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Message: "Тема удалена",
+	})
+}
+
+// MoveNoteTopicHandler godoc
+// @Security		BearerAuth
+// @Summary		Перенос заметки в тему
+// @Description	Перекладывает заметку в другую тему пользователя; topic_id = 0 убирает заметку из темы
+// @Tags			note
+// @Accept			json
+// @Produce		json
+// @Param			id		path		uint				true	"ID заметки"
+// @Param			input	body		MoveNoteTopicInput	true	"Тема"
+// @Success		200		{object}	response.NoteResponse	"Заметка после переноса"
+// @Failure		400		{object}	response.ErrorResponse	"Ошибка валидации VALIDATION_ERROR"
+// @Failure		404		{object}	response.ErrorResponse	"Заметка не найдена NOTE_NOT_FOUND, тема не найдена TOPIC_NOT_FOUND"
+// @Failure		500		{object}	response.ErrorResponse	"Ошибка БД DB_ERROR"
+// @Router			/notes/{id}/topic [put]
+func MoveNoteTopicHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input MoveNoteTopicInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Message: "Ошибка валидации данных",
+			Code:    "VALIDATION_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var note models.Note
+	if err := db.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&note).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Заметка не найдена",
+			Code:    "NOTE_NOT_FOUND",
+		})
+		return
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var topicID *uint
+		if input.TopicID != 0 {
+			// Строка темы блокируется до конца транзакции: удаление темы блокирует все темы пользователя
+			// и дождётся переноса (и переложит заметку вместе с остальными), а завершённое удаление
+			// сделает тему ненайденной, и заметка не останется ссылаться на удалённую тему
+			var ids []uint
+			if err := tx.Raw("SELECT id FROM topics WHERE id = ? AND user_id = ? AND deleted_at IS NULL FOR UPDATE", input.TopicID, userID).
+				Scan(&ids).Error; err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return errTopicNotFound
+			}
+			topicID = &input.TopicID
+		}
+		return tx.Model(&note).Update("topic_id", topicID).Error
+	})
+	if errors.Is(err, errTopicNotFound) {
+		c.JSON(topicErrorResponse(err))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при переносе заметки",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := db.DB.Where("id = ?", note.ID).Preload("Tags").Preload("Attachments").First(&note).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении заметки",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, noteToResponse(note))
+}
+
+// lockUserTopics блокирует все темы пользователя до конца транзакции перед изменением иерархии
+func lockUserTopics(tx *gorm.DB, userID uint) error {
+	return tx.Exec("SELECT id FROM topics WHERE user_id = ? FOR UPDATE", userID).Error
+}
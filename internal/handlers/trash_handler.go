@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/response"
+	"NeuroNest/internal/trash"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrashHandler godoc
+// @Security		BearerAuth
+// @Summary		Корзина
+// @Description	Возвращает удалённые заметки пользователя с датой окончательного удаления
+// @Tags			trash
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	response.TrashListResponse	"Заметки в корзине"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка при получении корзины DB_ERROR"
+// @Router			/notes/trash [get]
+func GetTrashHandler(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var notes []models.Note
+	if err := db.DB.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").
+		Preload("Tags").Preload("Attachments").
+		Find(&notes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при получении корзины",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	notesResp := make([]response.TrashNoteResponse, 0, len(notes))
+	for _, note := range notes {
+		notesResp = append(notesResp, response.TrashNoteResponse{
+			Note:      noteToResponse(note),
+			DeletedAt: note.DeletedAt.Time.Format("2006-01-02 15:04:05"),
+			PurgeAt:   trash.PurgeAt(note.DeletedAt.Time).Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	c.JSON(http.StatusOK, response.TrashListResponse{
+		Notes: notesResp,
+		Total: len(notesResp),
+	})
+}
+
+// RestoreNoteHandler godoc
+// @Security		BearerAuth
+// @Summary		Восстановление заметки из корзины
+// @Description	Возвращает удалённую заметку вместе с тегами и вложениями
+// @Tags			trash
+// @Accept			json
+// @Produce		json
+// @Param			id	path		uint	true	"ID заметки"
+// @Success		200	{object}	response.SuccessResponse	"Заметка восстановлена"
+// @Failure		404	{object}	response.ErrorResponse	"Заметка не найдена в корзине NOTE_NOT_FOUND"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка при восстановлении заметки DB_ERROR"
+// @Router			/notes/{id}/restore [post]
+func RestoreNoteHandler(c *gin.Context) {
+	noteID := c.Param("id")
+	userID := c.GetUint("userID")
+
+	var note models.Note
+	if err := db.DB.Unscoped().Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", noteID, userID).First(&note).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Заметка не найдена в корзине",
+			Code:    "NOTE_NOT_FOUND",
+		})
+		return
+	}
+
+	if err := db.DB.Unscoped().Model(&note).Update("deleted_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при восстановлении заметки",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Message: "Заметка восстановлена",
+	})
+}
+
+// PurgeNoteHandler godoc
+// @Security		BearerAuth
+// @Summary		Окончательное удаление заметки
+// @Description	Безвозвратно удаляет заметку из корзины вместе с вложениями, их файлами и историей ревизий
+// @Tags			trash
+// @Accept			json
+// @Produce		json
+// @Param			id	path		uint	true	"ID заметки"
+// @Success		200	{object}	response.SuccessResponse	"Заметка удалена окончательно"
+// @Failure		404	{object}	response.ErrorResponse	"Заметка не найдена в корзине NOTE_NOT_FOUND"
+// @Failure		500	{object}	response.ErrorResponse	"Ошибка при удалении заметки DB_ERROR"
+// @Router			/notes/{id}/purge [delete]
+func PurgeNoteHandler(c *gin.Context) {
+	noteID := c.Param("id")
+	userID := c.GetUint("userID")
+
+	var note models.Note
+	if err := db.DB.Unscoped().Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", noteID, userID).First(&note).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Message: "Заметка не найдена в корзине",
+			Code:    "NOTE_NOT_FOUND",
+		})
+		return
+	}
+
+	if err := trash.Purge(note); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Message: "Ошибка при удалении заметки",
+			Code:    "DB_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Message: "Заметка удалена окончательно",
+	})
+}
@@ -3,14 +3,19 @@ package models
 import (
 	"time"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
 type ChatHistory struct {
 	gorm.Model
-	UserID    uint   `gorm:"not null"`
-	Message   string `gorm:"not null"`
-	Response  string `gorm:"not null"`
-	Timestamp time.Time
-	Embedding []byte `gorm:"type:bytea"` // Векторное представление сообщения
+	UserID         uint   `gorm:"not null"`
+	ConversationID string `gorm:"index"` // Идентификатор диалога, объединяющий несколько обменов
+	Message        string `gorm:"not null"`
+	Response       string `gorm:"not null"`
+	Timestamp      time.Time
+	Embedding      []byte `gorm:"type:bytea"` // Векторное представление сообщения
+	EmbeddingModel string // URI модели, построившей эмбеддинг
+	EmbeddingDim   int
+	NoteIDs        pq.Int64Array `gorm:"type:integer[];default:'{}'"` // Заметки, на которые сослался ответ
 }
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// NoteChunk — фрагмент длинной заметки со своим эмбеддингом.
+// Смещения указаны в символах (рунах) содержимого заметки, EndOffset не включается
+type NoteChunk struct {
+	ID             uint   `gorm:"primarykey"`
+	NoteID         uint   `gorm:"not null;uniqueIndex:idx_note_chunk_index"`
+	UserID         uint   `gorm:"not null;index"`
+	ChunkIndex     int    `gorm:"not null;uniqueIndex:idx_note_chunk_index"` // Порядковый номер фрагмента в заметке
+	StartOffset    int    `gorm:"not null"`
+	EndOffset      int    `gorm:"not null"`
+	Text           string `gorm:"not null"`
+	Embedding      []byte `gorm:"type:bytea"` // JSON-массив, как в Note.Embedding
+	EmbeddingModel string `gorm:"index"`      // URI модели, построившей эмбеддинг; поиск сравнивает только фрагменты текущей модели
+	EmbeddingDim   int
+	CreatedAt      time.Time
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// EmbeddingCacheEntry — закэшированный эмбеддинг текста, ключ — SHA-256 нормализованного текста и URI модели
+type EmbeddingCacheEntry struct {
+	TextHash  string `gorm:"primaryKey;size:64"`
+	ModelURI  string `gorm:"primaryKey"`
+	Embedding []byte `gorm:"type:bytea;not null"` // JSON-массив, как в Note.Embedding
+	CreatedAt time.Time
+}
+
+func (EmbeddingCacheEntry) TableName() string {
+	return "embedding_cache"
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Типы фоновых AI-задач
+const (
+	AIJobEmbed     = "embed"     // Пересчёт эмбеддинга заметки
+	AIJobSummarize = "summarize" // Генерация резюме заметки
+	AIJobAutoTag   = "autotag"   // Автоматическое проставление предложенных тегов
+)
+
+// Состояния фоновой AI-задачи
+const (
+	AIJobPending = "pending" // Ожидает выполнения, в том числе повторного после ошибки
+	AIJobRunning = "running" // Выполняется воркером
+	AIJobDone    = "done"    // Выполнена успешно
+	AIJobDead    = "dead"    // Исчерпаны попытки, задача больше не повторяется
+)
+
+// AIJob — задача очереди AI-обработки заметок (эмбеддинги, резюме, автотеги)
+type AIJob struct {
+	gorm.Model
+	Type        string    `gorm:"not null;index"`
+	NoteID      uint      `gorm:"not null;index"`
+	UserID      uint      `gorm:"not null;index"`
+	Status      string    `gorm:"not null;default:pending;index:idx_ai_jobs_status_run_at"`
+	RunAt       time.Time `gorm:"not null;index:idx_ai_jobs_status_run_at"` // Не раньше этого момента задача может быть взята в работу
+	Attempts    int       `gorm:"not null;default:0"`                       // Сколько раз задача уже запускалась
+	MaxAttempts int       `gorm:"not null"`
+	LastError   string
+	LockedAt    *time.Time // Когда воркер взял задачу; по нему находятся зависшие задачи
+	FinishedAt  *time.Time
+}
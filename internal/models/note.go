@@ -9,24 +9,46 @@ import (
 
 type Note struct {
 	gorm.Model
-	UserID      uint          `gorm:"not null"`
-	Title       string        `gorm:"not null"`
-	Content     string        `gorm:"not null"`
-	Summary     string        // Суммаризация текста (можно генерировать на стороне AI)
-	Embedding   []byte        `gorm:"type:bytea"` // Векторное представление заметки
-	Attachments []Attachment  // Вложения к заметке
-	IsArchived  bool          // Архивная заметка или нет
-	Tags        []Tag         `gorm:"many2many:note_tags;"`        // Связь многие-ко-многим с тегами
-	RelatedIDs  pq.Int64Array `gorm:"type:integer[];default:'{}'"` // Связанные заметки (ID других заметок)
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	UserID          uint          `gorm:"not null"`
+	Title           string        `gorm:"not null"`
+	Content         string        `gorm:"not null"`
+	ContentHash     string        `gorm:"index"` // SHA-256 содержимого с нормализованными пробелами, для поиска точных дубликатов
+	Summary         string        // Суммаризация текста (можно генерировать на стороне AI)
+	SummaryStale    bool          // Резюме устарело: содержимое менялось после суммаризации
+	SummaryStatus   string        // Состояние фоновой генерации резюме: pending, processing, ready, failed
+	EmbeddingStatus string        `gorm:"default:ready"` // Состояние фонового расчёта эмбеддинга
+	Embedding       []byte        `gorm:"type:bytea"`    // Векторное представление заметки
+	EmbeddingModel  string        // URI модели, построившей эмбеддинг
+	EmbeddingDim    int           // Размерность эмбеддинга
+	TopicID         *uint         `gorm:"index"` // Тема (блокнот), в которой лежит заметка; nil — вне тем
+	Attachments     []Attachment  // Вложения к заметке
+	IsArchived      bool          // Архивная заметка или нет
+	Tags            []Tag         `gorm:"many2many:note_tags;"`        // Связь многие-ко-многим с тегами
+	RelatedIDs      pq.Int64Array `gorm:"type:integer[];default:'{}'"` // Связанные заметки (ID других заметок)
+	DuplicateIDs    pq.Int64Array `gorm:"type:integer[];default:'{}'"` // Дубликаты, найденные при последнем расчёте эмбеддинга
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
+// Состояния фоновой AI-обработки заметки (EmbeddingStatus, SummaryStatus)
+const (
+	AIStatusPending    = "pending"
+	AIStatusProcessing = "processing"
+	AIStatusReady      = "ready"
+	AIStatusFailed     = "failed"
+)
+
+// Tag — тег пользователя. Теги вложены друг в друга: Path — полный путь вида work/clients/acme,
+// Name — его последний сегмент. Пути уникальны в пределах пользователя (среди неудалённых тегов)
 type Tag struct {
 	gorm.Model
-	UserID      uint   `gorm:"not null;index"`
-	Name        string `gorm:"unique;not null"`
+	UserID      uint   `gorm:"not null;index;uniqueIndex:idx_tags_user_path,where:deleted_at IS NULL"`
+	ParentID    *uint  `gorm:"index"` // nil — тег верхнего уровня
+	Name        string `gorm:"not null"`
+	Path        string `gorm:"not null;uniqueIndex:idx_tags_user_path,where:deleted_at IS NULL"`
 	Description string
+	Color       string // Цвет в формате #RRGGBB
+	Icon        string // Иконка: эмодзи или имя иконки клиента
 	Notes       []Note `gorm:"many2many:note_tags;"` // Обратная связь с заметками
 }
 
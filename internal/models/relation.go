@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// NoteRelation — предложенная связанная заметка: один из ближайших соседей заметки по эмбеддингу.
+// Хранится отдельно от Note.RelatedIDs, которые задаёт пользователь, и пересчитывается при изменении заметки
+type NoteRelation struct {
+	NoteID         uint    `gorm:"primaryKey"`
+	RelatedID      uint    `gorm:"primaryKey;index"`
+	UserID         uint    `gorm:"not null;index"`
+	Score          float64 `gorm:"not null"` // Косинусное сходство эмбеддингов заметок
+	EmbeddingModel string  // URI модели, по эмбеддингам которой найден сосед
+	CreatedAt      time.Time
+}
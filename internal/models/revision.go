@@ -0,0 +1,26 @@
+package models
+
+import "gorm.io/gorm"
+
+// Действия, после которых сохраняется ревизия заметки
+const (
+	RevisionActionCreated    = "created"
+	RevisionActionUpdated    = "updated"
+	RevisionActionSummarized = "summarized"
+	RevisionActionArchived   = "archived"
+	RevisionActionRestored   = "restored"
+	RevisionActionMerged     = "merged"
+)
+
+// NoteRevision — снимок заголовка, содержимого и резюме заметки после очередной записи
+type NoteRevision struct {
+	gorm.Model
+	NoteID       uint   `gorm:"not null;uniqueIndex:idx_note_revision_number"`
+	UserID       uint   `gorm:"not null;index"`
+	Number       int    `gorm:"not null;uniqueIndex:idx_note_revision_number"` // Порядковый номер ревизии внутри заметки
+	Title        string `gorm:"not null"`
+	Content      string `gorm:"not null"`
+	Summary      string
+	SummaryStale bool
+	Action       string `gorm:"not null"` // Например: "created", "updated", "summarized"
+}
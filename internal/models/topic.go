@@ -0,0 +1,13 @@
+package models
+
+import "gorm.io/gorm"
+
+// Topic — блокнот (тема) пользователя. Темы вкладываются друг в друга через ParentID,
+// заметка лежит не более чем в одной теме (Note.TopicID)
+type Topic struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;index"`
+	ParentID    *uint  `gorm:"index"` // nil — тема верхнего уровня
+	Name        string `gorm:"not null"`
+	Description string
+}
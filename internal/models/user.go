@@ -12,4 +12,10 @@ type User struct {
 	LastName     string
 	ProfilePic   string // Ссылка на фото профиля
 	Role         string `gorm:"not null;default:'user'"` // Например: user, admin
+
+	AutoTagEnabled   bool    // Автоматически проставлять предложенные теги новым заметкам
+	AutoTagThreshold float64 `gorm:"default:0.8"` // Минимальная уверенность для автоматического проставления тега
 }
+
+// RoleAdmin роль администратора: доступ к служебной статистике сервера
+const RoleAdmin = "admin"
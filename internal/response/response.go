@@ -10,6 +10,15 @@ type ErrorResponse struct {
 	Details string `json:"details,omitempty"`
 }
 
+// InvalidReferencesResponse ошибка INVALID_REFERENCES: перечислены ID, которых нет у пользователя
+type InvalidReferencesResponse struct {
+	Message           string  `json:"message"`
+	Code              string  `json:"code" example:"INVALID_REFERENCES"`
+	InvalidTagIDs     []uint  `json:"invalid_tag_ids,omitempty"`
+	InvalidRelatedIDs []int64 `json:"invalid_related_ids,omitempty"`
+	InvalidTopicID    uint    `json:"invalid_topic_id,omitempty"`
+}
+
 type TokenResponse struct {
 	AccessToken  string `json:"access_token" example:"eyJhbGciOiJI..."`
 	RefreshToken string `json:"refresh_token" example:"eyJhbGciOi..."`
@@ -21,6 +30,9 @@ type ProfileResponse struct {
 	FirstName  string `json:"first_name,omitempty"`
 	LastName   string `json:"last_name,omitempty"`
 	ProfilePic string `json:"profile_pic,omitempty"` // Ссылка на фото профиля
+
+	AutoTagEnabled   bool    `json:"auto_tag_enabled"`   // Автоматически проставлять предложенные теги
+	AutoTagThreshold float64 `json:"auto_tag_threshold"` // Минимальная уверенность для автоматического проставления
 }
 
 type UploadAvatarResponse struct {
@@ -33,17 +45,21 @@ type SummarizeResponse struct {
 }
 
 type NoteResponse struct {
-	ID          uint              `json:"id"`
-	Title       string            `json:"title"`
-	Content     string            `json:"content"`
-	Summary     string            `json:"summary,omitempty"`
-	TopicID     uint              `json:"topic_id,omitempty"`
-	Attachments []AttachmentShort `json:"attachments,omitempty"`
-	IsArchived  bool              `json:"is_archived"`
-	Tags        []TagShort        `json:"tags,omitempty"`
-	RelatedIDs  []int64           `json:"related_ids,omitempty"`
-	CreatedAt   string            `json:"created_at"`
-	UpdatedAt   string            `json:"updated_at"`
+	ID              uint              `json:"id"`
+	Title           string            `json:"title"`
+	Content         string            `json:"content"`
+	Summary         string            `json:"summary,omitempty"`
+	SummaryStale    bool              `json:"summary_stale,omitempty"`                    // Резюме не соответствует текущему содержимому
+	SummaryStatus   string            `json:"summary_status,omitempty" example:"ready"`   // Фоновая генерация резюме: pending, processing, ready, failed
+	EmbeddingStatus string            `json:"embedding_status,omitempty" example:"ready"` // Фоновый расчёт эмбеддинга: pending, processing, ready, failed
+	TopicID         uint              `json:"topic_id,omitempty"`
+	Attachments     []AttachmentShort `json:"attachments,omitempty"`
+	IsArchived      bool              `json:"is_archived"`
+	Tags            []TagShort        `json:"tags,omitempty"`
+	RelatedIDs      []int64           `json:"related_ids,omitempty"`
+	DuplicateIDs    []int64           `json:"duplicate_ids,omitempty"` // Точные и почти дубликаты на момент последнего расчёта эмбеддинга
+	CreatedAt       string            `json:"created_at"`
+	UpdatedAt       string            `json:"updated_at"`
 }
 
 type AttachmentShort struct {
@@ -53,15 +69,106 @@ type AttachmentShort struct {
 	FileSize int64  `json:"file_size"`
 }
 
-type TagShort struct {
-	ID   uint   `json:"id"`
-	Name string `json:"name"`
+// RelatedNote связанная заметка: указанная пользователем (confirmed) или найденная по эмбеддингу (suggested)
+type RelatedNote struct {
+	ID     uint     `json:"id"`
+	Title  string   `json:"title"`
+	Source string   `json:"source" example:"suggested"` // confirmed, suggested
+	Score  *float64 `json:"score,omitempty"`            // Сходство эмбеддингов, если заметка среди ближайших соседей
 }
 
-type TagResponse struct {
+type RelatedNotesResponse struct {
+	NoteID  uint          `json:"note_id"`
+	Related []RelatedNote `json:"related"`
+	Total   int           `json:"total"`
+}
+
+// NoteCreatedResponse ответ на создание заметки. duplicate_ids — уже существующие заметки
+// с тем же содержимым; почти дубликаты появляются в duplicate_ids заметки после расчёта эмбеддинга
+type NoteCreatedResponse struct {
+	Message      string `json:"message"`
+	ID           uint   `json:"id"`
+	DuplicateIDs []uint `json:"duplicate_ids,omitempty"`
+}
+
+type DuplicateNote struct {
+	ID        uint   `json:"id"`
+	Title     string `json:"title"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// DuplicateCluster группа заметок-дубликатов
+type DuplicateCluster struct {
+	Notes      []DuplicateNote `json:"notes"`
+	Exact      bool            `json:"exact"`      // У всех заметок одинаковое содержимое
+	Similarity float64         `json:"similarity"` // Наименьшее сходство среди связей, объединивших кластер; 1 для точных дубликатов
+}
+
+type DuplicatesResponse struct {
+	Clusters []DuplicateCluster `json:"clusters"`
+	Total    int                `json:"total"`
+}
+
+type TopicResponse struct {
 	ID          uint   `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	ParentID    *uint  `json:"parent_id"` // null — тема верхнего уровня
+	CreatedAt   string `json:"created_at"`
+}
+
+type TopicsListResponse struct {
+	Topics []TopicResponse `json:"topics"`
+	Total  int             `json:"total"`
+}
+
+// TopicTreeNode тема с вложенными темами и числом заметок
+type TopicTreeNode struct {
+	ID             uint            `json:"id"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description"`
+	NoteCount      int64           `json:"note_count"`       // Заметки непосредственно в теме
+	TotalNoteCount int64           `json:"total_note_count"` // Заметки в теме и во всех вложенных
+	Children       []TopicTreeNode `json:"children"`
+}
+
+type TopicTreeResponse struct {
+	Topics        []TopicTreeNode `json:"topics"`
+	UnsortedCount int64           `json:"unsorted_count"` // Заметки вне тем
+}
+
+type TagShort struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Color string `json:"color,omitempty"`
+	Icon  string `json:"icon,omitempty"`
+}
+
+type TagResponse struct {
+	ID          uint    `json:"id"`
+	Name        string  `json:"name"`
+	Path        string  `json:"path" example:"work/clients/acme"`
+	ParentID    *uint   `json:"parent_id"` // null — тег верхнего уровня
+	Description string  `json:"description"`
+	Color       string  `json:"color,omitempty" example:"#4f46e5"`
+	Icon        string  `json:"icon,omitempty"`
+	NoteCount   int64   `json:"note_count"`             // Число заметок с тегом (без корзины)
+	LastUsedAt  *string `json:"last_used_at,omitempty"` // Когда тег последний раз поставили заметке
+
+	Children []TagResponse `json:"children,omitempty"` // Вложенные теги (в списке с tree=true)
+}
+
+type TagCreatedResponse struct {
+	Message string      `json:"message"`
+	Tag     TagResponse `json:"tag"`
+}
+
+// TagExistsResponse ошибка TAG_EXISTS: у пользователя уже есть тег с таким именем
+type TagExistsResponse struct {
+	Message string `json:"message"`
+	Code    string `json:"code" example:"TAG_EXISTS"`
+	TagID   uint   `json:"tag_id"` // ID существующего тега
 }
 
 type TagsListResponse struct {
@@ -69,7 +176,147 @@ type TagsListResponse struct {
 	Total int           `json:"total"`
 }
 
+// TagSuggestion предложенный тег. Для нового тега (is_new) tag_id не заполнен
+type TagSuggestion struct {
+	TagID          *uint    `json:"tag_id,omitempty"`
+	Name           string   `json:"name"`
+	Confidence     float64  `json:"confidence"` // 0..1, среднее по источникам из sources
+	IsNew          bool     `json:"is_new"`
+	Sources        []string `json:"sources"`                   // embedding, llm
+	EmbeddingScore *float64 `json:"embedding_score,omitempty"` // Сходство с заметками под этим тегом
+	LLMScore       *float64 `json:"llm_score,omitempty"`       // Уверенность языковой модели
+}
+
+type TagSuggestionsResponse struct {
+	NoteID      uint            `json:"note_id"`
+	Suggestions []TagSuggestion `json:"suggestions"`
+	Total       int             `json:"total"`
+}
+
 type NotesListResponse struct {
-	Notes []NoteResponse `json:"notes"`
-	Total int            `json:"total"`
+	Notes      []NoteResponse `json:"notes"`
+	Total      int            `json:"total"`                 // Общее число заметок, подходящих под фильтры
+	NextCursor string         `json:"next_cursor,omitempty"` // Курсор следующей страницы, пусто на последней
+}
+
+type SemanticSearchResult struct {
+	Note  NoteResponse `json:"note"`
+	Score float64      `json:"score" example:"0.87"` // Косинусное сходство с запросом
+	Chunk *ChunkMatch  `json:"chunk,omitempty"`      // Фрагмент заметки, лучше всего совпавший с запросом
+}
+
+// ChunkMatch — фрагмент содержимого заметки. Смещения start и end указаны в символах, end не включается
+type ChunkMatch struct {
+	Index int    `json:"index"`
+	Start int    `json:"start" example:"1200"`
+	End   int    `json:"end" example:"2650"`
+	Text  string `json:"text"`
+}
+
+type SemanticSearchResponse struct {
+	Results []SemanticSearchResult `json:"results"`
+	Total   int                    `json:"total"`
+}
+
+type RevisionResponse struct {
+	Number       int    `json:"number"`
+	Action       string `json:"action" example:"updated"`
+	Title        string `json:"title"`
+	Content      string `json:"content,omitempty"`
+	Summary      string `json:"summary,omitempty"`
+	SummaryStale bool   `json:"summary_stale,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type RevisionsListResponse struct {
+	Revisions []RevisionResponse `json:"revisions"`
+	Total     int                `json:"total"`
+}
+
+type RevisionDiffResponse struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Diff string `json:"diff"` // Unified diff по заголовку, содержимому и резюме
+}
+
+type TrashNoteResponse struct {
+	Note      NoteResponse `json:"note"`
+	DeletedAt string       `json:"deleted_at"`
+	PurgeAt   string       `json:"purge_at"` // Когда заметка будет удалена окончательно
+}
+
+type TrashListResponse struct {
+	Notes []TrashNoteResponse `json:"notes"`
+	Total int                 `json:"total"`
+}
+
+type TextSearchResult struct {
+	Note           NoteResponse `json:"note"`
+	Rank           float64      `json:"rank" example:"0.42"`                                // Ранг ts_rank_cd
+	TitleHighlight string       `json:"title_highlight" example:"Мои <mark>заметки</mark>"` // Заголовок с подсветкой совпадений, HTML-экранирован
+	Snippet        string       `json:"snippet"`                                            // Фрагменты текста с подсветкой <mark>, HTML-экранированы
+}
+
+type TextSearchResponse struct {
+	Results []TextSearchResult `json:"results"`
+	Total   int                `json:"total"`
+}
+
+type HybridSearchResult struct {
+	Note           NoteResponse `json:"note"`
+	Score          float64      `json:"score"`                              // Итоговая оценка после слияния
+	MatchedBy      []string     `json:"matched_by" example:"text,semantic"` // Сигналы, по которым найдена заметка
+	TextRank       float64      `json:"text_rank,omitempty"`                // Ранг полнотекстового поиска
+	SemanticScore  float64      `json:"semantic_score,omitempty"`           // Косинусное сходство с запросом
+	TitleHighlight string       `json:"title_highlight,omitempty"`          // Заголовок с подсветкой совпадений, HTML-экранирован
+	Snippet        string       `json:"snippet,omitempty"`                  // Фрагменты текста с подсветкой <mark>, HTML-экранированы
+	Chunk          *ChunkMatch  `json:"chunk,omitempty"`                    // Фрагмент, совпавший по семантическому сигналу
+}
+
+type HybridSearchResponse struct {
+	Method  string               `json:"method" example:"rrf"`
+	Results []HybridSearchResult `json:"results"`
+	Total   int                  `json:"total"`
+}
+
+type ChatCitation struct {
+	NoteID uint   `json:"note_id"`
+	Title  string `json:"title"`
+}
+
+type ChatResponse struct {
+	ConversationID string         `json:"conversation_id"`
+	Question       string         `json:"question"`
+	Answer         string         `json:"answer"`
+	Citations      []ChatCitation `json:"citations"` // Заметки, на которые опирается ответ
+	CreatedAt      string         `json:"created_at"`
+}
+
+type ChatConversationResponse struct {
+	ConversationID string `json:"conversation_id"`
+	Title          string `json:"title"` // Первый вопрос диалога
+	MessagesCount  int    `json:"messages_count"`
+	LastMessageAt  string `json:"last_message_at"`
+}
+
+type ChatConversationsListResponse struct {
+	Conversations []ChatConversationResponse `json:"conversations"`
+	Total         int                        `json:"total"`
+}
+
+type ChatMessagesListResponse struct {
+	ConversationID string         `json:"conversation_id"`
+	Messages       []ChatResponse `json:"messages"`
+	Total          int            `json:"total"`
+}
+
+type EmbeddingCacheStatsResponse struct {
+	Enabled       bool    `json:"enabled"`
+	Hits          int64   `json:"hits"`           // Всего попаданий с момента запуска сервера
+	MemoryHits    int64   `json:"memory_hits"`    // Попадания в LRU в памяти
+	StoreHits     int64   `json:"store_hits"`     // Попадания в таблицу embedding_cache
+	Misses        int64   `json:"misses"`         // Эмбеддинг запрошен у модели
+	HitRate       float64 `json:"hit_rate"`       // Доля попаданий, 0..1
+	MemoryEntries int     `json:"memory_entries"` // Записей в LRU
+	StoredEntries int64   `json:"stored_entries"` // Записей в таблице embedding_cache
 }
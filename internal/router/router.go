@@ -16,7 +16,7 @@ func RouterConfig() *gin.Engine {
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Authorization", "Content-Type"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
@@ -48,10 +48,51 @@ func RouterConfig() *gin.Engine {
 	{
 		noteGroup.POST("/create", handlers.CreateNoteHandler)
 		noteGroup.GET("/list", handlers.GetNotesHandler)
+		noteGroup.GET("/search", handlers.TextSearchHandler)
+		noteGroup.GET("/search/semantic", handlers.SemanticSearchHandler)
+		noteGroup.GET("/search/hybrid", handlers.HybridSearchHandler)
+		noteGroup.GET("/trash", handlers.GetTrashHandler)
+		noteGroup.GET("/duplicates", handlers.GetDuplicatesHandler)
 		noteGroup.GET("/:id", handlers.GetNoteHandler)
+		noteGroup.PUT("/:id", handlers.UpdateNoteHandler)
+		noteGroup.PATCH("/:id", handlers.UpdateNoteHandler)
 		noteGroup.DELETE("/:id", handlers.DeleteNoteHandler)
+		noteGroup.POST("/:id/restore", handlers.RestoreNoteHandler)
+		noteGroup.DELETE("/:id/purge", handlers.PurgeNoteHandler)
 		noteGroup.POST("/:id/summarize", handlers.SummarizeNoteByIDHandler)
+		noteGroup.GET("/:id/suggest-tags", handlers.SuggestTagsHandler)
+		noteGroup.GET("/:id/related", handlers.GetRelatedNotesHandler)
+		noteGroup.POST("/:id/merge", handlers.MergeNotesHandler)
+		noteGroup.PUT("/:id/topic", handlers.MoveNoteTopicHandler)
+		noteGroup.POST("/:id/tags/:tagId", handlers.AddNoteTagHandler)
+		noteGroup.DELETE("/:id/tags/:tagId", handlers.RemoveNoteTagHandler)
 		noteGroup.PATCH("/:id/archive", handlers.ArchiveNoteHandler)
+		noteGroup.GET("/:id/revisions", handlers.GetNoteRevisionsHandler)
+		noteGroup.GET("/:id/revisions/diff", handlers.DiffNoteRevisionsHandler)
+		noteGroup.GET("/:id/revisions/:number", handlers.GetNoteRevisionHandler)
+		noteGroup.POST("/:id/revisions/:number/restore", handlers.RestoreNoteRevisionHandler)
+	}
+
+	chatGroup := r.Group("/chat", auth.AuthMiddleware())
+	{
+		chatGroup.POST("", handlers.ChatHandler)
+		chatGroup.GET("/history", handlers.GetChatHistoryHandler)
+		chatGroup.GET("/history/:conversationId", handlers.GetChatConversationHandler)
+	}
+
+	aiGroup := r.Group("/ai", auth.AuthMiddleware())
+	{
+		aiGroup.GET("/embedding-cache/stats", auth.AdminMiddleware(), handlers.GetEmbeddingCacheStatsHandler)
+	}
+
+	topicGroup := r.Group("/topics", auth.AuthMiddleware())
+	{
+		topicGroup.POST("/create", handlers.CreateTopicHandler)
+		topicGroup.GET("/list", handlers.GetTopicsHandler)
+		topicGroup.GET("/tree", handlers.GetTopicTreeHandler)
+		topicGroup.GET("/:id", handlers.GetTopicHandler)
+		topicGroup.PATCH("/:id", handlers.UpdateTopicHandler)
+		topicGroup.DELETE("/:id", handlers.DeleteTopicHandler)
 	}
 
 	tagGroup := r.Group("/tags", auth.AuthMiddleware())
@@ -59,6 +100,8 @@ func RouterConfig() *gin.Engine {
 		tagGroup.POST("/create", handlers.CreateTagsHandler)
 		tagGroup.GET("/list", handlers.GetTagsHandler)
 		tagGroup.GET("/:id", handlers.GetTagHandler)
+		tagGroup.PATCH("/:id", handlers.UpdateTagHandler)
+		tagGroup.POST("/:id/merge", handlers.MergeTagHandler)
 		tagGroup.DELETE("/:id", handlers.DeleteTagHandler)
 	}
 	return r
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/service"
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
+
+// Pair — две заметки с косинусным сходством эмбеддингов, NoteID < OtherID
+type Pair struct {
+	NoteID  uint
+	OtherID uint
+	Score   float64
+}
+
+// duplicateNeighbours сколько ближайших соседей каждой заметки проверяется в режиме pgvector
+const duplicateNeighbours = 10
+
+// SimilarPairs находит пары неархивных заметок пользователя, сходство эмбеддингов которых (модели model)
+// не меньше minScore. С pgvector для каждой заметки проверяются только ближайшие соседи из ANN-индекса
+func SimilarPairs(userID uint, model string, minScore float64) ([]Pair, error) {
+	if db.VectorEnabled {
+		var pairs []Pair
+		err := db.VectorSearch(func(tx *gorm.DB) error {
+			return tx.Raw(`SELECT a.id AS note_id, b.id AS other_id, 1 - (a.embedding_vec <=> b.embedding_vec) AS score
+			FROM notes a
+			CROSS JOIN LATERAL (
+				SELECT n.id, n.embedding_vec FROM notes n
+				WHERE n.user_id = a.user_id AND n.id <> a.id AND n.deleted_at IS NULL AND n.is_archived = false
+					AND n.embedding_model = a.embedding_model AND n.embedding_vec IS NOT NULL
+				ORDER BY n.embedding_vec <=> a.embedding_vec
+				LIMIT ?
+			) b
+			WHERE a.user_id = ? AND a.deleted_at IS NULL AND a.is_archived = false
+				AND a.embedding_model = ? AND a.embedding_vec IS NOT NULL
+				AND a.id < b.id AND 1 - (a.embedding_vec <=> b.embedding_vec) >= ?`,
+				duplicateNeighbours, userID, model, minScore).Scan(&pairs).Error
+		})
+		return pairs, err
+	}
+
+	var notes []struct {
+		ID        uint
+		Embedding []byte
+	}
+	err := db.DB.Model(&models.Note{}).
+		Select("id, embedding").
+		Where("user_id = ? AND is_archived = ? AND embedding_model = ? AND embedding IS NOT NULL", userID, false, model).
+		Order("id").
+		Scan(&notes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(notes))
+	vectors := make([][]float64, 0, len(notes))
+	for _, note := range notes {
+		var v []float64
+		if err := json.Unmarshal(note.Embedding, &v); err != nil {
+			continue
+		}
+		ids = append(ids, note.ID)
+		vectors = append(vectors, v)
+	}
+
+	var pairs []Pair
+	for i := range vectors {
+		for j := i + 1; j < len(vectors); j++ {
+			if score := service.CosineSimilarity(vectors[i], vectors[j]); score >= minScore {
+				pairs = append(pairs, Pair{NoteID: ids[i], OtherID: ids[j], Score: score})
+			}
+		}
+	}
+	return pairs, nil
+}
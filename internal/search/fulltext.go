@@ -0,0 +1,60 @@
+package search
+
+import (
+	"NeuroNest/internal/db"
+)
+
+// TextHit — заметка, найденная полнотекстовым поиском, с подсвеченными фрагментами
+type TextHit struct {
+	NoteID         uint
+	Rank           float64
+	TitleHighlight string
+	Snippet        string
+}
+
+// TextOptions параметры полнотекстового поиска
+type TextOptions struct {
+	Limit           int
+	Offset          int
+	IncludeArchived bool
+}
+
+// tsQuery объединяет разбор запроса в русской и английской конфигурациях,
+// чтобы слово находилось в любой словоформе на обоих языках
+const tsQuery = "(websearch_to_tsquery('russian', @q) || websearch_to_tsquery('english', @q))"
+
+// headlineOptions параметры ts_headline для подсветки совпадений
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10, FragmentDelimiter=\" … \""
+
+// htmlEscapeSQL экранирует HTML в текстовом SQL-выражении. Заголовок и содержимое экранируются
+// до ts_headline: тогда единственная разметка в подсветке — её собственные <mark>
+func htmlEscapeSQL(expr string) string {
+	return `replace(replace(replace(replace(` + expr + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')`
+}
+
+// FullText ищет заметки пользователя по колонке search_vector и ранжирует их по ts_rank_cd
+func FullText(userID uint, query string, opts TextOptions) ([]TextHit, error) {
+	archived := ""
+	if !opts.IncludeArchived {
+		archived = "AND NOT is_archived"
+	}
+
+	sql := `SELECT id AS note_id,
+			ts_rank_cd(search_vector, ` + tsQuery + `) AS rank,
+			ts_headline('russian', ` + htmlEscapeSQL("title") + `, ` + tsQuery + `, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>') AS title_highlight,
+			ts_headline('russian', ` + htmlEscapeSQL("coalesce(nullif(content, ''), summary)") + `, ` + tsQuery + `, '` + headlineOptions + `') AS snippet
+		FROM notes
+		WHERE user_id = @user AND deleted_at IS NULL ` + archived + `
+			AND search_vector @@ ` + tsQuery + `
+		ORDER BY rank DESC, id DESC
+		LIMIT @limit OFFSET @offset`
+
+	var hits []TextHit
+	err := db.DB.Raw(sql, map[string]interface{}{
+		"q":      query,
+		"user":   userID,
+		"limit":  opts.Limit,
+		"offset": opts.Offset,
+	}).Scan(&hits).Error
+	return hits, err
+}
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"sort"
+)
+
+// Способы объединения полнотекстового и семантического ранжирования
+const (
+	MethodRRF      = "rrf"      // Reciprocal Rank Fusion: сумма 1/(k + позиция) по каждому сигналу
+	MethodWeighted = "weighted" // Взвешенная сумма нормированного ранга и косинусного сходства
+)
+
+// Сигналы, по которым заметка попала в выдачу гибридного поиска
+const (
+	SignalText     = "text"
+	SignalSemantic = "semantic"
+)
+
+// HybridHit — результат гибридного поиска с разбором вклада каждого сигнала
+type HybridHit struct {
+	NoteID         uint
+	Score          float64
+	MatchedBy      []string
+	TextRank       float64
+	SemanticScore  float64
+	TitleHighlight string
+	Snippet        string
+	Chunk          *ChunkMatch // Фрагмент, совпавший по семантическому сигналу
+}
+
+// HybridOptions параметры гибридного поиска
+type HybridOptions struct {
+	Limit            int
+	IncludeArchived  bool
+	Method           string  // MethodRRF или MethodWeighted
+	TextWeight       float64 // Вес полнотекстового сигнала для MethodWeighted (0..1)
+	RRFK             int     // Константа k для MethodRRF
+	SemanticMinScore float64 // Минимальное сходство, при котором семантический сигнал считается совпадением
+}
+
+// Hybrid объединяет полнотекстовый поиск и поиск по эмбеддингам в одну выдачу
+func Hybrid(userID uint, query string, queryEmb []float64, opts HybridOptions) ([]HybridHit, error) {
+	// Берём с запасом кандидатов от каждого сигнала, чтобы слияние было осмысленным
+	pool := opts.Limit * 3
+	if pool < 50 {
+		pool = 50
+	}
+
+	textHits, err := FullText(userID, query, TextOptions{Limit: pool, IncludeArchived: opts.IncludeArchived})
+	if err != nil {
+		return nil, err
+	}
+	semanticHits, err := Semantic(userID, queryEmb, SemanticOptions{Limit: pool, IncludeArchived: opts.IncludeArchived})
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[uint]*HybridHit)
+	get := func(id uint) *HybridHit {
+		if h, ok := merged[id]; ok {
+			return h
+		}
+		h := &HybridHit{NoteID: id}
+		merged[id] = h
+		return h
+	}
+
+	var maxRank float64
+	for _, th := range textHits {
+		if th.Rank > maxRank {
+			maxRank = th.Rank
+		}
+	}
+
+	for pos, th := range textHits {
+		h := get(th.NoteID)
+		h.MatchedBy = append(h.MatchedBy, SignalText)
+		h.TextRank = th.Rank
+		h.TitleHighlight = th.TitleHighlight
+		h.Snippet = th.Snippet
+		if opts.Method == MethodWeighted {
+			if maxRank > 0 {
+				h.Score += opts.TextWeight * th.Rank / maxRank
+			}
+		} else {
+			h.Score += 1 / float64(opts.RRFK+pos+1)
+		}
+	}
+
+	pos := 0
+	for _, sh := range semanticHits {
+		if sh.Score < opts.SemanticMinScore {
+			continue
+		}
+		h := get(sh.NoteID)
+		h.MatchedBy = append(h.MatchedBy, SignalSemantic)
+		h.SemanticScore = sh.Score
+		h.Chunk = sh.Chunk
+		if opts.Method == MethodWeighted {
+			h.Score += (1 - opts.TextWeight) * sh.Score
+		} else {
+			h.Score += 1 / float64(opts.RRFK+pos+1)
+		}
+		pos++
+	}
+
+	hits := make([]HybridHit, 0, len(merged))
+	for _, h := range merged {
+		hits = append(hits, *h)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].NoteID > hits[j].NoteID
+	})
+	if opts.Limit > 0 && len(hits) > opts.Limit {
+		hits = hits[:opts.Limit]
+	}
+	return hits, nil
+}
@@ -0,0 +1,70 @@
+package search
+
+import (
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/service"
+	"encoding/json"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Neighbours возвращает limit заметок пользователя, ближайших к эмбеддингу emb заметки noteID.
+// Сравниваются эмбеддинги заметок целиком, посчитанные моделью model; архивные и удалённые заметки не учитываются
+func Neighbours(userID, noteID uint, emb []float64, model string, limit int) ([]Hit, error) {
+	notesQuery := func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.Note{}).
+			Where("user_id = ? AND id <> ? AND is_archived = ? AND embedding_model = ?", userID, noteID, false, model)
+	}
+
+	if db.VectorEnabled {
+		vec := db.VectorLiteral(emb)
+		var rows []struct {
+			NoteID uint
+			Score  float64
+		}
+		err := db.VectorSearch(func(tx *gorm.DB) error {
+			return notesQuery(tx).Select("id AS note_id, 1 - (embedding_vec <=> ?::vector) AS score", vec).
+				Where("embedding_vec IS NOT NULL").
+				Order(clause.Expr{SQL: "embedding_vec <=> ?::vector", Vars: []interface{}{vec}}).
+				Limit(limit).
+				Scan(&rows).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+		hits := make([]Hit, 0, len(rows))
+		for _, row := range rows {
+			hits = append(hits, Hit{NoteID: row.NoteID, Score: row.Score})
+		}
+		sort.SliceStable(hits, func(i, j int) bool {
+			return hits[i].Score > hits[j].Score
+		})
+		return hits, nil
+	}
+
+	var notes []struct {
+		ID        uint
+		Embedding []byte
+	}
+	if err := notesQuery(db.DB).Select("id, embedding").Where("embedding IS NOT NULL").Scan(&notes).Error; err != nil {
+		return nil, err
+	}
+	hits := make([]Hit, 0, len(notes))
+	for _, note := range notes {
+		var v []float64
+		if err := json.Unmarshal(note.Embedding, &v); err != nil {
+			continue
+		}
+		hits = append(hits, Hit{NoteID: note.ID, Score: service.CosineSimilarity(emb, v)})
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
@@ -0,0 +1,152 @@
+package search
+
+import (
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"NeuroNest/internal/service"
+	"encoding/json"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Hit — заметка с оценкой релевантности запросу
+type Hit struct {
+	NoteID uint
+	Score  float64
+	Chunk  *ChunkMatch // Лучше всего совпавший фрагмент заметки
+}
+
+// ChunkMatch — фрагмент заметки, по которому она найдена. Смещения — в символах содержимого заметки
+type ChunkMatch struct {
+	Index int
+	Start int
+	End   int
+	Text  string
+}
+
+// SemanticOptions параметры семантического поиска
+type SemanticOptions struct {
+	Limit           int
+	IncludeArchived bool
+}
+
+// chunkFanout во сколько раз больше фрагментов, чем нужно заметок, выбирается из ANN-индекса:
+// у одной заметки может совпасть несколько фрагментов
+const chunkFanout = 4
+
+// chunkRow — фрагмент с оценкой сходства из выборки
+type chunkRow struct {
+	NoteID      uint
+	ChunkIndex  int
+	StartOffset int
+	EndOffset   int
+	Text        string
+	Score       float64
+}
+
+// Semantic ранжирует заметки пользователя по косинусному сходству вектора запроса
+// с эмбеддингами их фрагментов. Оценка заметки — сходство лучшего фрагмента
+func Semantic(userID uint, query []float64, opts SemanticOptions) ([]Hit, error) {
+	if db.VectorEnabled {
+		return semanticPgvector(userID, query, opts)
+	}
+	return semanticScan(userID, query, opts)
+}
+
+// chunksQuery выбирает фрагменты неудалённых заметок пользователя с учётом архива.
+// Фрагменты, посчитанные другой моделью (до переиндексации), не сравниваются с запросом
+func chunksQuery(tx *gorm.DB, userID uint, opts SemanticOptions) *gorm.DB {
+	q := tx.Model(&models.NoteChunk{}).
+		Joins("JOIN notes ON notes.id = note_chunks.note_id AND notes.deleted_at IS NULL").
+		Where("note_chunks.user_id = ?", userID)
+	if model, err := service.EmbeddingModel(service.EmbeddingDocument); err == nil {
+		q = q.Where("note_chunks.embedding_model = ?", model)
+	}
+	if !opts.IncludeArchived {
+		q = q.Where("notes.is_archived = ?", false)
+	}
+	return q
+}
+
+// semanticPgvector ищет ближайшие фрагменты через ANN-индекс pgvector
+func semanticPgvector(userID uint, query []float64, opts SemanticOptions) ([]Hit, error) {
+	vec := db.VectorLiteral(query)
+	var rows []chunkRow
+	err := db.VectorSearch(func(tx *gorm.DB) error {
+		q := chunksQuery(tx, userID, opts).
+			Select("note_chunks.note_id, note_chunks.chunk_index, note_chunks.start_offset, note_chunks.end_offset, note_chunks.text, 1 - (note_chunks.embedding_vec <=> ?::vector) AS score", vec).
+			Where("note_chunks.embedding_vec IS NOT NULL").
+			Order(clause.Expr{SQL: "note_chunks.embedding_vec <=> ?::vector", Vars: []interface{}{vec}})
+		if opts.Limit > 0 {
+			q = q.Limit(opts.Limit * chunkFanout)
+		}
+		return q.Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Score > rows[j].Score
+	})
+	return bestChunks(rows, opts.Limit), nil
+}
+
+// semanticScan перебирает JSON-эмбеддинги фрагментов в Go (режим без pgvector)
+func semanticScan(userID uint, query []float64, opts SemanticOptions) ([]Hit, error) {
+	var chunks []struct {
+		chunkRow
+		Embedding []byte
+	}
+	err := chunksQuery(db.DB, userID, opts).
+		Select("note_chunks.note_id, note_chunks.chunk_index, note_chunks.start_offset, note_chunks.end_offset, note_chunks.text, note_chunks.embedding").
+		Where("note_chunks.embedding IS NOT NULL").
+		Scan(&chunks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]chunkRow, 0, len(chunks))
+	for _, chunk := range chunks {
+		var emb []float64
+		if err := json.Unmarshal(chunk.Embedding, &emb); err != nil {
+			// битый эмбеддинг не должен ломать весь поиск
+			continue
+		}
+		row := chunk.chunkRow
+		row.Score = service.CosineSimilarity(query, emb)
+		rows = append(rows, row)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Score > rows[j].Score
+	})
+	return bestChunks(rows, opts.Limit), nil
+}
+
+// bestChunks оставляет для каждой заметки лучший фрагмент. rows должны быть отсортированы по убыванию сходства
+func bestChunks(rows []chunkRow, limit int) []Hit {
+	seen := make(map[uint]bool)
+	hits := make([]Hit, 0, len(rows))
+	for _, row := range rows {
+		if seen[row.NoteID] {
+			continue
+		}
+		seen[row.NoteID] = true
+		hits = append(hits, Hit{
+			NoteID: row.NoteID,
+			Score:  row.Score,
+			Chunk: &ChunkMatch{
+				Index: row.ChunkIndex,
+				Start: row.StartOffset,
+				End:   row.EndOffset,
+				Text:  row.Text,
+			},
+		})
+		if limit > 0 && len(hits) == limit {
+			break
+		}
+	}
+	return hits
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/service"
+	"encoding/json"
+)
+
+// TagScore — сходство заметки с тегом: косинус между её эмбеддингом и центроидом заметок с этим тегом
+type TagScore struct {
+	TagID uint
+	Score float64
+	Notes int // По скольким заметкам посчитан центроид
+}
+
+// TagSimilarity сравнивает эмбеддинг заметки с центроидами тегов пользователя.
+// Сама заметка в центроиды не входит; учитываются только эмбеддинги модели model
+func TagSimilarity(userID, noteID uint, emb []float64, model string) ([]TagScore, error) {
+	if db.VectorEnabled {
+		return tagSimilarityPgvector(userID, noteID, emb, model)
+	}
+	return tagSimilarityScan(userID, noteID, emb, model)
+}
+
+const taggedNotesSQL = `FROM note_tags
+	JOIN notes ON notes.id = note_tags.note_id AND notes.deleted_at IS NULL
+	JOIN tags ON tags.id = note_tags.tag_id AND tags.deleted_at IS NULL
+	WHERE notes.user_id = ? AND tags.user_id = ? AND notes.id <> ? AND notes.embedding_model = ?`
+
+func tagSimilarityPgvector(userID, noteID uint, emb []float64, model string) ([]TagScore, error) {
+	var scores []TagScore
+	err := db.DB.Raw(`SELECT note_tags.tag_id, COUNT(*) AS notes,
+		1 - (AVG(notes.embedding_vec) <=> ?::vector) AS score `+taggedNotesSQL+`
+		AND notes.embedding_vec IS NOT NULL
+		GROUP BY note_tags.tag_id`,
+		db.VectorLiteral(emb), userID, userID, noteID, model).Scan(&scores).Error
+	return scores, err
+}
+
+func tagSimilarityScan(userID, noteID uint, emb []float64, model string) ([]TagScore, error) {
+	var rows []struct {
+		TagID     uint
+		Embedding []byte
+	}
+	err := db.DB.Raw(`SELECT note_tags.tag_id, notes.embedding `+taggedNotesSQL+`
+		AND notes.embedding IS NOT NULL`,
+		userID, userID, noteID, model).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byTag := make(map[uint][][]float64)
+	var order []uint
+	for _, row := range rows {
+		var v []float64
+		if err := json.Unmarshal(row.Embedding, &v); err != nil {
+			continue
+		}
+		if _, ok := byTag[row.TagID]; !ok {
+			order = append(order, row.TagID)
+		}
+		byTag[row.TagID] = append(byTag[row.TagID], v)
+	}
+
+	scores := make([]TagScore, 0, len(order))
+	for _, tagID := range order {
+		vectors := byTag[tagID]
+		scores = append(scores, TagScore{
+			TagID: tagID,
+			Score: service.CosineSimilarity(emb, service.MeanEmbedding(vectors)),
+			Notes: len(vectors),
+		})
+	}
+	return scores, nil
+}
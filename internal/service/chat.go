@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxContextNoteLen ограничение длины одной заметки в контексте запроса к модели
+const maxContextNoteLen = 4000
+
+// ContextNote — заметка, передаваемая модели как контекст для ответа
+type ContextNote struct {
+	ID    uint
+	Title string
+	Text  string
+}
+
+// ChatTurn — предыдущий обмен в диалоге
+type ChatTurn struct {
+	Question string
+	Answer   string
+}
+
+var citationRe = regexp.MustCompile(`\[note:(\d+)\]`)
+
+// AnswerQuestion отвечает на вопрос пользователя по его заметкам и возвращает ответ
+// вместе с ID заметок, на которые модель сослалась
+func AnswerQuestion(question string, notes []ContextNote, history []ChatTurn) (string, []uint, error) {
+	llm, err := DefaultCompleter()
+	if err != nil {
+		return "", nil, err
+	}
+
+	answer, err := llm.Complete(context.Background(), chatRequest(question, notes, history))
+	if err != nil {
+		return "", nil, err
+	}
+	return answer, ExtractCitations(answer, notes), nil
+}
+
+// AnswerQuestionStream работает как AnswerQuestion, но передаёт текст ответа в onDelta по мере генерации
+func AnswerQuestionStream(ctx context.Context, question string, notes []ContextNote, history []ChatTurn, onDelta func(string) error) (string, []uint, error) {
+	llm, err := DefaultCompleter()
+	if err != nil {
+		return "", nil, err
+	}
+
+	answer, err := llm.CompleteStream(ctx, chatRequest(question, notes, history), onDelta)
+	if err != nil {
+		return "", nil, err
+	}
+	return answer, ExtractCitations(answer, notes), nil
+}
+
+func chatRequest(question string, notes []ContextNote, history []ChatTurn) CompletionRequest {
+	return CompletionRequest{
+		Temperature: 0.2,
+		MaxTokens:   1500,
+		Messages:    chatMessages(question, notes, history),
+	}
+}
+
+// chatMessages собирает системную инструкцию, историю диалога и вопрос с контекстом заметок
+func chatMessages(question string, notes []ContextNote, history []ChatTurn) []Message {
+	messages := []Message{
+		{
+			Role: RoleSystem,
+			Text: "Вы — помощник, который отвечает на вопросы пользователя только на основе его заметок. " +
+				"Каждая заметка начинается с метки [note:ID]. Ссылайтесь на использованные заметки этими метками прямо в тексте ответа. " +
+				"Если в заметках нет ответа, честно скажите об этом.",
+		},
+	}
+	for _, turn := range history {
+		messages = append(messages,
+			Message{Role: RoleUser, Text: turn.Question},
+			Message{Role: RoleAssistant, Text: turn.Answer},
+		)
+	}
+
+	var b strings.Builder
+	b.WriteString("Заметки:\n\n")
+	for _, note := range notes {
+		text := []rune(note.Text)
+		if len(text) > maxContextNoteLen {
+			text = text[:maxContextNoteLen]
+		}
+		fmt.Fprintf(&b, "[note:%d] %s\n%s\n\n", note.ID, note.Title, string(text))
+	}
+	fmt.Fprintf(&b, "Вопрос: %s", question)
+
+	return append(messages, Message{Role: RoleUser, Text: b.String()})
+}
+
+// ExtractCitations возвращает ID заметок из меток [note:ID] в ответе.
+// Учитываются только заметки, переданные в контексте; если модель не поставила ни одной метки,
+// источниками считаются все заметки контекста
+func ExtractCitations(answer string, notes []ContextNote) []uint {
+	known := make(map[uint]bool, len(notes))
+	for _, note := range notes {
+		known[note.ID] = true
+	}
+
+	var cited []uint
+	seen := make(map[uint]bool)
+	for _, m := range citationRe.FindAllStringSubmatch(answer, -1) {
+		id, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil || !known[uint(id)] || seen[uint(id)] {
+			continue
+		}
+		seen[uint(id)] = true
+		cited = append(cited, uint(id))
+	}
+
+	if len(cited) == 0 {
+		for _, note := range notes {
+			cited = append(cited, note.ID)
+		}
+	}
+	return cited
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// EmbeddingStore — постоянное хранилище кэша эмбеддингов (таблица в Postgres)
+type EmbeddingStore interface {
+	// GetEmbedding возвращает сохранённый эмбеддинг; ok = false, если записи нет
+	GetEmbedding(textHash, modelURI string) (embedding []float64, ok bool, err error)
+	PutEmbedding(textHash, modelURI string, embedding []float64) error
+}
+
+// EmbeddingCacheStats — счётчики кэша с момента запуска сервера
+type EmbeddingCacheStats struct {
+	MemoryHits int64 // Найдено в памяти (LRU)
+	StoreHits  int64 // Найдено в Postgres
+	Misses     int64 // Эмбеддинг пришлось запросить у модели
+	Entries    int   // Текущий размер LRU
+}
+
+// EmbeddingCache кэширует эмбеддинги по SHA-256 нормализованного текста и URI модели:
+// одинаковый текст не отправляется модели повторно. LRU в памяти (необязательный) стоит перед хранилищем
+type EmbeddingCache struct {
+	store EmbeddingStore
+
+	mu      sync.Mutex
+	size    int
+	order   *list.List // Элементы *lruEntry, в начале — самые свежие
+	entries map[string]*list.Element
+
+	memoryHits atomic.Int64
+	storeHits  atomic.Int64
+	misses     atomic.Int64
+}
+
+type lruEntry struct {
+	key       string
+	embedding []float64
+}
+
+// NewEmbeddingCache создаёт кэш с LRU на lruSize записей (0 — без LRU) поверх store (nil — только память)
+func NewEmbeddingCache(lruSize int, store EmbeddingStore) *EmbeddingCache {
+	return &EmbeddingCache{
+		store:   store,
+		size:    lruSize,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// NormalizeEmbeddingText приводит текст к виду, по которому считается ключ кэша:
+// обрезаются пробелы по краям, последовательности пробельных символов схлопываются в один пробел
+func NormalizeEmbeddingText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// HashEmbeddingText — SHA-256 нормализованного текста в hex
+func HashEmbeddingText(text string) string {
+	sum := sha256.Sum256([]byte(NormalizeEmbeddingText(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Embed возвращает эмбеддинг из кэша или запрашивает его у e и сохраняет.
+// Ошибки хранилища не мешают получить эмбеддинг: они только логируются
+func (c *EmbeddingCache) Embed(ctx context.Context, e Embedder, text string, kind EmbeddingKind) ([]float64, error) {
+	textHash := HashEmbeddingText(text)
+	modelURI := e.ModelURI(kind)
+	key := modelURI + "|" + textHash
+
+	if emb, ok := c.lruGet(key); ok {
+		c.memoryHits.Add(1)
+		return emb, nil
+	}
+
+	if c.store != nil {
+		emb, ok, err := c.store.GetEmbedding(textHash, modelURI)
+		if err != nil {
+			log.Printf("Ошибка чтения кэша эмбеддингов: %v", err)
+		} else if ok {
+			c.storeHits.Add(1)
+			c.lruPut(key, emb)
+			return emb, nil
+		}
+	}
+
+	c.misses.Add(1)
+	emb, err := e.Embed(ctx, text, kind)
+	if err != nil {
+		return nil, err
+	}
+	if c.store != nil {
+		if err := c.store.PutEmbedding(textHash, modelURI, emb); err != nil {
+			log.Printf("Ошибка записи в кэш эмбеддингов: %v", err)
+		}
+	}
+	c.lruPut(key, emb)
+	return emb, nil
+}
+
+// Stats возвращает счётчики попаданий и промахов
+func (c *EmbeddingCache) Stats() EmbeddingCacheStats {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+	return EmbeddingCacheStats{
+		MemoryHits: c.memoryHits.Load(),
+		StoreHits:  c.storeHits.Load(),
+		Misses:     c.misses.Load(),
+		Entries:    entries,
+	}
+}
+
+func (c *EmbeddingCache) lruGet(key string) ([]float64, bool) {
+	if c.size <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruEntry).embedding, true
+}
+
+func (c *EmbeddingCache) lruPut(key string, embedding []float64) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*lruEntry).embedding = embedding
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, embedding: embedding})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
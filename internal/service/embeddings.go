@@ -1,27 +1,51 @@
 package service
 
 import (
-	"NeuroNest/internal/config"
 	"context"
-
-	"github.com/sheeiavellie/go-yandexgpt"
 )
 
-func GenerateEmbedding(text string) ([]float64, error) {
-	ctx := context.Background()
-	client := GetClient(ctx)
-	modelURI := yandexgpt.MakeEmbModelURI(config.CatalogID, yandexgpt.TextSearchQuery)
+// embeddingCache, если задан, используется при расчёте эмбеддингов для чтения и записи
+var embeddingCache *EmbeddingCache
+
+// SetEmbeddingCache включает кэш эмбеддингов (nil — отключает)
+func SetEmbeddingCache(c *EmbeddingCache) {
+	embeddingCache = c
+}
 
-	// 5. Делаем запрос на embedding :contentReference[oaicite:4]{index=4}
-	req := yandexgpt.YandexGPTEmbeddingsRequest{
-		ModelURI: modelURI,
-		Text:     text,
+// GetEmbeddingCacheStats возвращает счётчики кэша эмбеддингов; ok = false, если кэш отключён
+func GetEmbeddingCacheStats() (EmbeddingCacheStats, bool) {
+	if embeddingCache == nil {
+		return EmbeddingCacheStats{}, false
 	}
-	resp, err := client.GetEmbedding(ctx, req)
+	return embeddingCache.Stats(), true
+}
+
+// GenerateDocumentEmbedding строит эмбеддинг содержимого заметки моделью для документов
+func GenerateDocumentEmbedding(text string) ([]float64, error) {
+	return generateEmbedding(text, EmbeddingDocument)
+}
+
+// GenerateQueryEmbedding строит эмбеддинг поискового запроса или вопроса моделью для запросов
+func GenerateQueryEmbedding(text string) ([]float64, error) {
+	return generateEmbedding(text, EmbeddingQuery)
+}
+
+// EmbeddingModel возвращает URI модели, которой сейчас строятся эмбеддинги вида kind
+func EmbeddingModel(kind EmbeddingKind) (string, error) {
+	e, err := DefaultEmbedder()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
+	return e.ModelURI(kind), nil
+}
 
-	emb := resp.Embedding
-	return emb, nil
+func generateEmbedding(text string, kind EmbeddingKind) ([]float64, error) {
+	e, err := DefaultEmbedder()
+	if err != nil {
+		return nil, err
+	}
+	if embeddingCache != nil {
+		return embeddingCache.Embed(context.Background(), e, text, kind)
+	}
+	return e.Embed(context.Background(), text, kind)
 }
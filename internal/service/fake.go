@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// FakeProvider — детерминированная реализация без сетевых вызовов для тестов и локальной разработки.
+// Ответ — сокращённый текст последнего сообщения пользователя, эмбеддинг — хеширование слов в вектор,
+// поэтому тексты с общими словами получаются близкими.
+type FakeProvider struct {
+	Dim int
+}
+
+func NewFakeProvider(dim int) *FakeProvider {
+	if dim <= 0 {
+		dim = 256
+	}
+	return &FakeProvider{Dim: dim}
+}
+
+// fakeAnswerLen длина ответа в рунах
+const fakeAnswerLen = 200
+
+func (p *FakeProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	var last string
+	for _, m := range req.Messages {
+		if m.Role == RoleUser {
+			last = m.Text
+		}
+	}
+	text := []rune(strings.TrimSpace(last))
+	if len(text) > fakeAnswerLen {
+		text = text[:fakeAnswerLen]
+	}
+	return "[fake] " + string(text), nil
+}
+
+// CompleteStream отдаёт ответ Complete по словам
+func (p *FakeProvider) CompleteStream(ctx context.Context, req CompletionRequest, onDelta func(string) error) (string, error) {
+	text, err := p.Complete(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	for _, word := range strings.SplitAfter(text, " ") {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if err := onDelta(word); err != nil {
+			return "", err
+		}
+	}
+	return text, nil
+}
+
+func (p *FakeProvider) ModelURI(kind EmbeddingKind) string {
+	return fmt.Sprintf("fake://hashing/%d", p.Dim)
+}
+
+func (p *FakeProvider) Embed(ctx context.Context, text string, kind EmbeddingKind) ([]float64, error) {
+	vec := make([]float64, p.Dim)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		vec[h.Sum32()%uint32(p.Dim)]++
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vec {
+			vec[i] /= norm
+		}
+	}
+	return vec, nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// yandexIAMTokenURL адрес обмена JWT сервисного аккаунта на IAM-токен (он же audience JWT)
+const yandexIAMTokenURL = "https://iam.api.cloud.yandex.net/iam/v1/tokens"
+
+// TokenSource выдаёт действующий IAM-токен для запросов к Yandex Cloud
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticTokenSource — заранее полученный токен (для разработки). Не обновляется.
+type StaticTokenSource string
+
+func (s StaticTokenSource) Token(ctx context.Context) (string, error) {
+	if s == "" {
+		return "", errors.New("IAM token is not set")
+	}
+	return string(s), nil
+}
+
+// ServiceAccountKey — авторизованный ключ сервисного аккаунта (JSON, выдаваемый `yc iam key create`)
+type ServiceAccountKey struct {
+	ID               string `json:"id"`
+	ServiceAccountID string `json:"service_account_id"`
+	PrivateKey       string `json:"private_key"`
+}
+
+// LoadServiceAccountKey читает авторизованный ключ из JSON-файла
+func LoadServiceAccountKey(path string) (*ServiceAccountKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var key ServiceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, err
+	}
+	if key.ID == "" || key.ServiceAccountID == "" || key.PrivateKey == "" {
+		return nil, errors.New("service account key must contain id, service_account_id and private_key")
+	}
+	return &key, nil
+}
+
+// ServiceAccountTokenSource обменивает подписанный PS256 JWT сервисного аккаунта на IAM-токен
+// и обновляет его заранее: не реже MaxAge и не позже чем за ExpiryMargin до истечения.
+type ServiceAccountTokenSource struct {
+	Endpoint     string        // По умолчанию yandexIAMTokenURL
+	MaxAge       time.Duration // Yandex рекомендует обновлять токен не реже раза в час
+	ExpiryMargin time.Duration
+	HTTPClient   *http.Client
+
+	key        *ServiceAccountKey
+	privateKey *rsa.PrivateKey
+	now        func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	refreshAt time.Time
+}
+
+func NewServiceAccountTokenSource(key *ServiceAccountKey) (*ServiceAccountTokenSource, error) {
+	// Ключ из Yandex Cloud начинается со служебной строки перед PEM-блоком
+	pemData := key.PrivateKey
+	if i := strings.Index(pemData, "-----BEGIN"); i > 0 {
+		pemData = pemData[i:]
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(pemData))
+	if err != nil {
+		return nil, fmt.Errorf("parse service account private key: %w", err)
+	}
+
+	return &ServiceAccountTokenSource{
+		Endpoint:     yandexIAMTokenURL,
+		MaxAge:       time.Hour,
+		ExpiryMargin: 5 * time.Minute,
+		HTTPClient:   &http.Client{Timeout: 30 * time.Second},
+		key:          key,
+		privateKey:   privateKey,
+		now:          time.Now,
+	}, nil
+}
+
+// Token возвращает закешированный токен или получает новый.
+// Если обновление не удалось, а старый токен ещё действует, возвращается старый.
+func (s *ServiceAccountTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.token != "" && now.Before(s.refreshAt) {
+		return s.token, nil
+	}
+
+	token, expiresAt, err := s.exchange(ctx, now)
+	if err != nil {
+		if s.token != "" && now.Before(s.expiresAt) {
+			log.Printf("Не удалось обновить IAM-токен, используется текущий: %v", err)
+			return s.token, nil
+		}
+		return "", err
+	}
+
+	s.token = token
+	s.expiresAt = expiresAt
+	s.refreshAt = now.Add(s.MaxAge)
+	if margin := expiresAt.Add(-s.ExpiryMargin); margin.Before(s.refreshAt) {
+		s.refreshAt = margin
+	}
+	return s.token, nil
+}
+
+func (s *ServiceAccountTokenSource) exchange(ctx context.Context, now time.Time) (string, time.Time, error) {
+	claims := jwt.RegisteredClaims{
+		Issuer:    s.key.ServiceAccountID,
+		Audience:  jwt.ClaimStrings{yandexIAMTokenURL},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodPS256, claims)
+	jwtToken.Header["kid"] = s.key.ID
+	signed, err := jwtToken.SignedString(s.privateKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	body, err := json.Marshal(map[string]string{"jwt": signed})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", time.Time{}, fmt.Errorf("IAM token exchange failed. Http Status %d %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var out struct {
+		IAMToken  string    `json:"iamToken"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", time.Time{}, err
+	}
+	if out.IAMToken == "" {
+		return "", time.Time{}, errors.New("IAM token exchange returned empty token")
+	}
+	if out.ExpiresAt.IsZero() {
+		out.ExpiresAt = now.Add(12 * time.Hour)
+	}
+	return out.IAMToken, out.ExpiresAt, nil
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// iamStub — локальная замена эндпоинта обмена JWT на IAM-токен
+type iamStub struct {
+	t         *testing.T
+	publicKey *rsa.PublicKey
+	calls     int32
+	fail      atomic.Bool
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+func (s *iamStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := atomic.AddInt32(&s.calls, 1)
+	if s.fail.Load() {
+		http.Error(w, `{"message":"unavailable"}`, http.StatusServiceUnavailable)
+		return
+	}
+
+	var body struct {
+		JWT string `json:"jwt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.t.Errorf("decode request: %v", err)
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	token, err := jwt.Parse(body.JWT, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodPS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Method.Alg())
+		}
+		if token.Header["kid"] != "key-id" {
+			return nil, fmt.Errorf("unexpected kid %v", token.Header["kid"])
+		}
+		return s.publicKey, nil
+	}, jwt.WithAudience(yandexIAMTokenURL), jwt.WithIssuer("sa-id"), jwt.WithTimeFunc(s.now))
+	if err != nil || !token.Valid {
+		s.t.Errorf("invalid jwt: %v", err)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"iamToken":  fmt.Sprintf("token-%d", n),
+		"expiresAt": s.now().Add(s.ttl).Format(time.RFC3339),
+	})
+}
+
+func newTestTokenSource(t *testing.T, ttl time.Duration) (*ServiceAccountTokenSource, *iamStub, *time.Time) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	clock := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	now := func() time.Time { return clock }
+
+	stub := &iamStub{t: t, publicKey: &privateKey.PublicKey, ttl: ttl, now: now}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	src, err := NewServiceAccountTokenSource(&ServiceAccountKey{
+		ID:               "key-id",
+		ServiceAccountID: "sa-id",
+		// Как в файле из Yandex Cloud: служебная строка перед PEM-блоком
+		PrivateKey: "PLEASE DO NOT REMOVE THIS LINE! Yandex.Cloud SA Key ID <key-id>\n" + string(pemKey),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.Endpoint = server.URL
+	src.now = now
+	return src, stub, &clock
+}
+
+func TestServiceAccountTokenSourceCachesToken(t *testing.T) {
+	src, stub, _ := newTestTokenSource(t, 12*time.Hour)
+
+	for i := 0; i < 3; i++ {
+		token, err := src.Token(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "token-1" {
+			t.Fatalf("token = %q, want token-1", token)
+		}
+	}
+	if stub.calls != 1 {
+		t.Fatalf("exchange calls = %d, want 1", stub.calls)
+	}
+}
+
+func TestServiceAccountTokenSourceRefreshesAfterMaxAge(t *testing.T) {
+	src, stub, clock := newTestTokenSource(t, 12*time.Hour)
+
+	if _, err := src.Token(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	*clock = clock.Add(59 * time.Minute)
+	if token, _ := src.Token(context.Background()); token != "token-1" {
+		t.Fatalf("token before MaxAge = %q, want token-1", token)
+	}
+
+	*clock = clock.Add(2 * time.Minute)
+	token, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "token-2" || stub.calls != 2 {
+		t.Fatalf("token = %q after %d calls, want token-2 after 2", token, stub.calls)
+	}
+}
+
+func TestServiceAccountTokenSourceRefreshesBeforeExpiry(t *testing.T) {
+	src, stub, clock := newTestTokenSource(t, 20*time.Minute)
+
+	if _, err := src.Token(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Токен живёт 20 минут, обновление — за ExpiryMargin (5 минут) до истечения
+	*clock = clock.Add(16 * time.Minute)
+	token, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "token-2" || stub.calls != 2 {
+		t.Fatalf("token = %q after %d calls, want token-2 after 2", token, stub.calls)
+	}
+}
+
+func TestServiceAccountTokenSourceKeepsValidTokenOnRefreshError(t *testing.T) {
+	src, stub, clock := newTestTokenSource(t, 12*time.Hour)
+
+	if _, err := src.Token(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	stub.fail.Store(true)
+	*clock = clock.Add(2 * time.Hour)
+	token, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error while old token is valid: %v", err)
+	}
+	if token != "token-1" {
+		t.Fatalf("token = %q, want token-1", token)
+	}
+
+	*clock = clock.Add(11 * time.Hour)
+	if _, err := src.Token(context.Background()); err == nil {
+		t.Fatal("expected error after the old token expired")
+	}
+}
+
+func TestStaticTokenSource(t *testing.T) {
+	token, err := StaticTokenSource("static").Token(context.Background())
+	if err != nil || token != "static" {
+		t.Fatalf("Token() = %q, %v", token, err)
+	}
+	if _, err := StaticTokenSource("").Token(context.Background()); err == nil {
+		t.Fatal("expected error for empty token")
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAIProvider — генерация текста и эмбеддинги через OpenAI-совместимый HTTP API
+// (OpenAI, llama.cpp server, Ollama, vLLM и т. п.)
+type OpenAIProvider struct {
+	BaseURL        string // Например: "http://localhost:11434/v1"
+	APIKey         string // Необязателен для локальных серверов
+	ChatModel      string
+	EmbeddingModel string
+	HTTPClient     *http.Client
+}
+
+func NewOpenAIProvider(baseURL, apiKey, chatModel, embeddingModel string) *OpenAIProvider {
+	return &OpenAIProvider{
+		BaseURL:        strings.TrimRight(baseURL, "/"),
+		APIKey:         apiKey,
+		ChatModel:      chatModel,
+		EmbeddingModel: embeddingModel,
+		HTTPClient:     &http.Client{},
+	}
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIChatRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature float32         `json:"temperature"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	Stream      bool            `json:"stream"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+		Delta   openAIMessage `json:"delta"`
+	} `json:"choices"`
+}
+
+type openAIEmbeddingRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (p *OpenAIProvider) chatRequest(req CompletionRequest, stream bool) openAIChatRequest {
+	messages := make([]openAIMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		messages = append(messages, openAIMessage{Role: m.Role, Content: m.Text})
+	}
+	return openAIChatRequest{
+		Model:       p.ChatModel,
+		Messages:    messages,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		Stream:      stream,
+	}
+}
+
+// post отправляет JSON-запрос и возвращает ответ с успешным статусом; тело закрывает вызывающий
+func (p *OpenAIProvider) post(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	resp, err := p.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("bad response. Http Status %d %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+func (p *OpenAIProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	resp, err := p.post(ctx, "/chat/completions", p.chatRequest(req, false))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out openAIChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	if len(out.Choices) == 0 {
+		return "", errors.New("empty completion response")
+	}
+	return out.Choices[0].Message.Content, nil
+}
+
+// CompleteStream читает поток "data: {...}" до "data: [DONE]"
+func (p *OpenAIProvider) CompleteStream(ctx context.Context, req CompletionRequest, onDelta func(string) error) (string, error) {
+	resp, err := p.post(ctx, "/chat/completions", p.chatRequest(req, true))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk openAIChatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return text.String(), err
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		delta := chunk.Choices[0].Delta.Content
+		text.WriteString(delta)
+		if err := onDelta(delta); err != nil {
+			return text.String(), err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return text.String(), err
+	}
+
+	if text.Len() == 0 {
+		return "", errors.New("empty completion response")
+	}
+	return text.String(), nil
+}
+
+// ModelURI включает адрес сервера: под одним именем разные серверы могут отдавать разные модели.
+// Документы и запросы кодируются одной моделью
+func (p *OpenAIProvider) ModelURI(kind EmbeddingKind) string {
+	return p.BaseURL + "/" + p.EmbeddingModel
+}
+
+func (p *OpenAIProvider) Embed(ctx context.Context, text string, kind EmbeddingKind) ([]float64, error) {
+	resp, err := p.post(ctx, "/embeddings", openAIEmbeddingRequest{Model: p.EmbeddingModel, Input: text})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out openAIEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if len(out.Data) == 0 {
+		return nil, errors.New("empty embedding response")
+	}
+	return out.Data[0].Embedding, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"NeuroNest/internal/config"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Роли сообщений в запросе к языковой модели
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// ErrProviderNotConfigured — провайдер модели не инициализирован (см. Init)
+var ErrProviderNotConfigured = errors.New("LLM provider is not configured")
+
+// Message — одно сообщение диалога с моделью
+type Message struct {
+	Role string
+	Text string
+}
+
+// CompletionRequest — запрос на генерацию текста, не зависящий от провайдера
+type CompletionRequest struct {
+	Messages    []Message
+	Temperature float32
+	MaxTokens   int
+}
+
+// Completer генерирует текст по сообщениям
+type Completer interface {
+	Complete(ctx context.Context, req CompletionRequest) (string, error)
+	// CompleteStream вызывает onDelta для каждого нового фрагмента и возвращает весь текст
+	CompleteStream(ctx context.Context, req CompletionRequest, onDelta func(string) error) (string, error)
+}
+
+// EmbeddingKind — назначение эмбеддинга. Документы и поисковые запросы могут кодироваться
+// разными моделями, векторы которых сравнимы между собой (например, text-search-doc и text-search-query)
+type EmbeddingKind int
+
+const (
+	EmbeddingDocument EmbeddingKind = iota // Заметки и их фрагменты
+	EmbeddingQuery                         // Поисковые запросы и вопросы к заметкам
+)
+
+// Embedder строит векторное представление текста
+type Embedder interface {
+	Embed(ctx context.Context, text string, kind EmbeddingKind) ([]float64, error)
+	// ModelURI идентифицирует модель эмбеддингов для kind: векторы разных моделей несравнимы
+	ModelURI(kind EmbeddingKind) string
+}
+
+var (
+	completer Completer
+	embedder  Embedder
+)
+
+// Init создаёт провайдера модели по config.LLMProvider: yandex, openai или fake,
+// и оборачивает его в ResilientProvider с политикой из конфигурации.
+// При ошибке AI-функции возвращают ErrProviderNotConfigured, но сервер продолжает работать.
+func Init() error {
+	var c Completer
+	var e Embedder
+	switch config.LLMProvider {
+	case "", "yandex":
+		tokens, err := yandexTokenSource()
+		if err != nil {
+			return err
+		}
+		p, err := NewYandexProvider(tokens, config.CatalogID)
+		if err != nil {
+			return err
+		}
+		c, e = p, p
+	case "openai":
+		p := NewOpenAIProvider(config.OpenAIBaseURL, config.OpenAIAPIKey, config.OpenAIChatModel, config.OpenAIEmbeddingModel)
+		c, e = p, p
+	case "fake":
+		p := NewFakeProvider(config.EmbeddingDim)
+		c, e = p, p
+	default:
+		return fmt.Errorf("unknown LLM provider %q", config.LLMProvider)
+	}
+
+	p := NewResilientProvider(c, e, policyFromConfig())
+	SetProviders(p, p)
+	return nil
+}
+
+// policyFromConfig собирает политику таймаутов, повторов и circuit breaker из конфигурации
+func policyFromConfig() Policy {
+	return Policy{
+		CompleteTimeout: time.Duration(config.AICompleteTimeoutSeconds) * time.Second,
+		StreamTimeout:   time.Duration(config.AIStreamTimeoutSeconds) * time.Second,
+		EmbedTimeout:    time.Duration(config.AIEmbedTimeoutSeconds) * time.Second,
+		MaxRetries:      config.AIMaxRetries,
+		RetryBaseDelay:  500 * time.Millisecond,
+		RetryMaxDelay:   10 * time.Second,
+		BreakerFailures: config.AIBreakerFailures,
+		BreakerCooldown: time.Duration(config.AIBreakerCooldownSeconds) * time.Second,
+	}
+}
+
+// yandexTokenSource выбирает источник IAM-токена: авторизованный ключ сервисного аккаунта
+// (с автоматическим обновлением) или статический IAM_TOKEN для разработки
+func yandexTokenSource() (TokenSource, error) {
+	if config.YandexSAKeyFile != "" {
+		key, err := LoadServiceAccountKey(config.YandexSAKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewServiceAccountTokenSource(key)
+	}
+	if config.IAMtoken == "" {
+		return nil, errors.New("neither YANDEX_SA_KEY_FILE nor IAM_TOKEN is set")
+	}
+	return StaticTokenSource(config.IAMtoken), nil
+}
+
+// SetProviders задаёт используемые реализации генерации текста и эмбеддингов
+func SetProviders(c Completer, e Embedder) {
+	completer = c
+	embedder = e
+}
+
+// DefaultCompleter возвращает провайдера генерации текста, выбранного в Init
+func DefaultCompleter() (Completer, error) {
+	if completer == nil {
+		return nil, ErrProviderNotConfigured
+	}
+	return completer, nil
+}
+
+// DefaultEmbedder возвращает провайдера эмбеддингов, выбранного в Init
+func DefaultEmbedder() (Embedder, error) {
+	if embedder == nil {
+		return nil, ErrProviderNotConfigured
+	}
+	return embedder, nil
+}
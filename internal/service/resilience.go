@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Типизированные ошибки обращения к модели. Проверяются через errors.Is,
+// исходная ошибка провайдера доступна через errors.Unwrap
+var (
+	ErrAIUnavailable = errors.New("AI provider is unavailable")
+	ErrAIRateLimited = errors.New("AI provider rate limit exceeded")
+	ErrAITimeout     = errors.New("AI provider request timed out")
+)
+
+// AIError — ошибка провайдера, отнесённая к одному из видов ErrAIUnavailable, ErrAIRateLimited, ErrAITimeout
+type AIError struct {
+	Kind error
+	Err  error
+}
+
+func (e *AIError) Error() string { return e.Kind.Error() + ": " + e.Err.Error() }
+
+func (e *AIError) Unwrap() error { return e.Err }
+
+func (e *AIError) Is(target error) bool { return target == e.Kind }
+
+// Policy — таймауты, повторы и параметры circuit breaker для обращений к модели
+type Policy struct {
+	CompleteTimeout time.Duration // Таймаут одной попытки генерации текста
+	StreamTimeout   time.Duration // Таймаут потоковой генерации целиком
+	EmbedTimeout    time.Duration // Таймаут одной попытки расчёта эмбеддинга
+	MaxRetries      int           // Сколько раз повторять запрос после 429, 5xx и сетевых ошибок
+	RetryBaseDelay  time.Duration // Базовая задержка перед повтором, удваивается с каждой попыткой
+	RetryMaxDelay   time.Duration
+	BreakerFailures int           // После стольких отказов подряд breaker размыкается
+	BreakerCooldown time.Duration // Сколько breaker остаётся разомкнутым, прежде чем пропустить пробный запрос
+}
+
+// httpStatusRe вытаскивает HTTP-статус из ошибок провайдеров и go-yandexgpt ("bad response. Http Status 429 ...")
+var httpStatusRe = regexp.MustCompile(`Http Status (\d{3})`)
+
+func httpStatus(err error) int {
+	m := httpStatusRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+	code, _ := strconv.Atoi(m[1])
+	return code
+}
+
+// malformedResponse — ответ провайдера не разобрался как JSON: так выглядят 5xx от шлюза с HTML или пустым телом,
+// которые go-yandexgpt возвращает сырой ошибкой декодирования, а также оборванный посреди тела ответ
+func malformedResponse(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// classify относит ошибку провайдера к виду AIError. Ошибки запроса (4xx, кроме 429) возвращаются как есть.
+// Неразобранный ответ провайдера считается временной недоступностью: статус у такой ошибки не сохраняется
+func classify(err error) error {
+	if err == nil || errors.Is(err, ErrAIUnavailable) || errors.Is(err, ErrAIRateLimited) || errors.Is(err, ErrAITimeout) {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &AIError{Kind: ErrAITimeout, Err: err}
+	}
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return &AIError{Kind: ErrAITimeout, Err: err}
+		}
+		return &AIError{Kind: ErrAIUnavailable, Err: err}
+	}
+	switch status := httpStatus(err); {
+	case status == 429:
+		return &AIError{Kind: ErrAIRateLimited, Err: err}
+	case status >= 500:
+		return &AIError{Kind: ErrAIUnavailable, Err: err}
+	case status == 0 && malformedResponse(err):
+		return &AIError{Kind: ErrAIUnavailable, Err: err}
+	}
+	return err
+}
+
+// retryable — можно ли повторить запрос после такой (уже классифицированной) ошибки
+func retryable(err error) bool {
+	return errors.Is(err, ErrAIRateLimited) || errors.Is(err, ErrAIUnavailable) || errors.Is(err, ErrAITimeout)
+}
+
+// countsAsOutage — считается ли ошибка признаком недоступности провайдера для circuit breaker.
+// Превышение лимита запросов не означает, что провайдер лежит
+func countsAsOutage(err error) bool {
+	return errors.Is(err, ErrAIUnavailable) || errors.Is(err, ErrAITimeout)
+}
+
+// circuitBreaker размыкается после серии отказов подряд и не пускает запросы к провайдеру до истечения cooldown.
+// После этого пропускается один пробный запрос: успех замыкает breaker, отказ снова размыкает
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.now().Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *circuitBreaker) record(err error) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if errors.Is(err, context.Canceled) {
+		// Клиент отменил запрос: о доступности провайдера это ничего не говорит,
+		// и счётчик отказов подряд не должен сбрасываться
+		return
+	}
+	if err == nil || !countsAsOutage(err) {
+		// Провайдер ответил, пусть даже ошибкой запроса: он доступен
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// ResilientProvider оборачивает провайдера модели: таймауты по типу вызова,
+// повторы с jitter при 429/5xx и сетевых сбоях, общий circuit breaker и типизированные ошибки
+type ResilientProvider struct {
+	completer Completer
+	embedder  Embedder
+	policy    Policy
+	breaker   *circuitBreaker
+}
+
+func NewResilientProvider(c Completer, e Embedder, policy Policy) *ResilientProvider {
+	return &ResilientProvider{
+		completer: c,
+		embedder:  e,
+		policy:    policy,
+		breaker: &circuitBreaker{
+			threshold: policy.BreakerFailures,
+			cooldown:  policy.BreakerCooldown,
+			now:       time.Now,
+		},
+	}
+}
+
+// do выполняет attempt с таймаутом на каждую попытку и повторяет его, пока ошибка временная.
+// canRetry (если задан) может запретить повтор, например когда часть ответа уже отдана клиенту
+func (p *ResilientProvider) do(ctx context.Context, timeout time.Duration, attempt func(ctx context.Context) error, canRetry func() bool) error {
+	var err error
+	for i := 0; ; i++ {
+		if !p.breaker.allow() {
+			return &AIError{Kind: ErrAIUnavailable, Err: errors.New("circuit breaker is open")}
+		}
+
+		err = p.attempt(ctx, timeout, attempt)
+		p.breaker.record(err)
+		if err == nil || !retryable(err) || i >= p.policy.MaxRetries || ctx.Err() != nil || (canRetry != nil && !canRetry()) {
+			return err
+		}
+
+		select {
+		case <-time.After(p.retryDelay(i)):
+		case <-ctx.Done():
+			return classify(ctx.Err())
+		}
+	}
+}
+
+func (p *ResilientProvider) attempt(ctx context.Context, timeout time.Duration, attempt func(ctx context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return classify(attempt(ctx))
+}
+
+// retryDelay — экспоненциальная задержка с full jitter: случайное значение от 0 до base*2^i
+func (p *ResilientProvider) retryDelay(i int) time.Duration {
+	d := p.policy.RetryBaseDelay
+	for j := 0; j < i && d < p.policy.RetryMaxDelay; j++ {
+		d *= 2
+	}
+	if p.policy.RetryMaxDelay > 0 && d > p.policy.RetryMaxDelay {
+		d = p.policy.RetryMaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+func (p *ResilientProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	var text string
+	err := p.do(ctx, p.policy.CompleteTimeout, func(ctx context.Context) error {
+		var err error
+		text, err = p.completer.Complete(ctx, req)
+		return err
+	}, nil)
+	return text, err
+}
+
+// CompleteStream повторяет запрос, только пока клиенту не отправлено ни одного фрагмента:
+// иначе повтор продублировал бы уже показанный текст
+func (p *ResilientProvider) CompleteStream(ctx context.Context, req CompletionRequest, onDelta func(string) error) (string, error) {
+	var text string
+	sent := false
+	err := p.do(ctx, p.policy.StreamTimeout, func(ctx context.Context) error {
+		var err error
+		text, err = p.completer.CompleteStream(ctx, req, func(delta string) error {
+			sent = true
+			return onDelta(delta)
+		})
+		return err
+	}, func() bool { return !sent })
+	return text, err
+}
+
+func (p *ResilientProvider) ModelURI(kind EmbeddingKind) string {
+	return p.embedder.ModelURI(kind)
+}
+
+func (p *ResilientProvider) Embed(ctx context.Context, text string, kind EmbeddingKind) ([]float64, error) {
+	var embedding []float64
+	err := p.do(ctx, p.policy.EmbedTimeout, func(ctx context.Context) error {
+		var err error
+		embedding, err = p.embedder.Embed(ctx, text, kind)
+		return err
+	}, nil)
+	return embedding, err
+}
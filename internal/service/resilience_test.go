@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	// Так go-yandexgpt отвечает на 5xx от шлюза с HTML или пустым телом
+	syntaxErr := json.Unmarshal([]byte("<html>502 Bad Gateway</html>"), &struct{}{})
+	emptyBodyErr := json.NewDecoder(strings.NewReader("")).Decode(&struct{}{})
+
+	tests := []struct {
+		name string
+		err  error
+		want error // nil — ошибка возвращается без классификации
+	}{
+		{name: "429", err: errors.New("bad response. Http Status 429 Too Many Requests"), want: ErrAIRateLimited},
+		{name: "500", err: errors.New("bad response. Http Status 500 Internal"), want: ErrAIUnavailable},
+		{name: "503", err: errors.New("bad response. Http Status 503 message unavailable"), want: ErrAIUnavailable},
+		{name: "400 не повторяется", err: errors.New("bad response. Http Status 400 message bad"), want: nil},
+		{name: "HTML вместо JSON", err: syntaxErr, want: ErrAIUnavailable},
+		{name: "пустое тело", err: emptyBodyErr, want: ErrAIUnavailable},
+		{name: "оборванное тело", err: fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), want: ErrAIUnavailable},
+		{name: "таймаут контекста", err: context.DeadlineExceeded, want: ErrAITimeout},
+		{name: "сетевой таймаут", err: &net.OpError{Op: "dial", Err: timeoutErr{}}, want: ErrAITimeout},
+		{name: "сетевая ошибка", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: ErrAIUnavailable},
+		{name: "прочая ошибка", err: errors.New("catalog ID is not set"), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classify(tt.err)
+			if tt.want == nil {
+				if got != tt.err {
+					t.Fatalf("classify(%v) = %v, want исходную ошибку", tt.err, got)
+				}
+				return
+			}
+			if !errors.Is(got, tt.want) {
+				t.Fatalf("classify(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("classify(%v) потеряла исходную ошибку", tt.err)
+			}
+		})
+	}
+
+	if got := classify(context.Canceled); got != context.Canceled {
+		t.Errorf("classify(context.Canceled) = %v", got)
+	}
+	if got := classify(nil); got != nil {
+		t.Errorf("classify(nil) = %v", got)
+	}
+}
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
+
+func TestRetryDelay(t *testing.T) {
+	p := &ResilientProvider{policy: Policy{RetryBaseDelay: 100 * time.Millisecond, RetryMaxDelay: time.Second}}
+	for i, limit := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		limit *= time.Millisecond
+		for n := 0; n < 50; n++ {
+			if d := p.retryDelay(i); d < 0 || d > limit {
+				t.Fatalf("retryDelay(%d) = %v, want 0..%v", i, d, limit)
+			}
+		}
+	}
+
+	zero := &ResilientProvider{}
+	if d := zero.retryDelay(3); d != 0 {
+		t.Errorf("retryDelay без базовой задержки = %v", d)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := &circuitBreaker{threshold: 2, cooldown: time.Minute, now: func() time.Time { return now }}
+	outage := &AIError{Kind: ErrAIUnavailable, Err: errors.New("down")}
+
+	// Лимит запросов и ошибки запроса не размыкают breaker
+	b.record(&AIError{Kind: ErrAIRateLimited, Err: errors.New("429")})
+	b.record(outage)
+	b.record(errors.New("bad request"))
+	b.record(outage)
+	if !b.allow() {
+		t.Fatal("breaker разомкнут после одного отказа подряд")
+	}
+
+	// Отмена запроса клиентом не сбрасывает счётчик отказов
+	b.record(context.Canceled)
+	b.record(fmt.Errorf("embed: %w", context.Canceled))
+	b.record(outage)
+	if b.allow() {
+		t.Fatal("breaker не разомкнулся после двух отказов подряд")
+	}
+
+	// После cooldown пропускается только один пробный запрос
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("пробный запрос не пропущен после cooldown")
+	}
+	if b.allow() {
+		t.Fatal("пропущен второй запрос во время пробного")
+	}
+	b.record(outage)
+	if b.allow() {
+		t.Fatal("breaker не разомкнулся после неудачного пробного запроса")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("пробный запрос не пропущен после второго cooldown")
+	}
+	b.record(nil)
+	if !b.allow() || !b.allow() {
+		t.Fatal("breaker не замкнулся после успешного пробного запроса")
+	}
+
+	disabled := &circuitBreaker{now: time.Now}
+	for i := 0; i < 10; i++ {
+		disabled.record(outage)
+	}
+	if !disabled.allow() {
+		t.Error("breaker с threshold = 0 разомкнулся")
+	}
+}
+
+// embedStub возвращает ошибки из errs по очереди, затем эмбеддинг
+type embedStub struct {
+	errs  []error
+	calls int
+}
+
+func (s *embedStub) Embed(ctx context.Context, text string, kind EmbeddingKind) ([]float64, error) {
+	s.calls++
+	if s.calls <= len(s.errs) {
+		return nil, s.errs[s.calls-1]
+	}
+	return []float64{1, 0}, nil
+}
+
+func (s *embedStub) ModelURI(kind EmbeddingKind) string { return "stub" }
+
+func TestResilientProviderRetriesMalformedResponse(t *testing.T) {
+	stub := &embedStub{errs: []error{
+		&json.SyntaxError{Offset: 1},
+		errors.New("bad response. Http Status 502 Bad Gateway"),
+	}}
+	p := NewResilientProvider(nil, stub, Policy{MaxRetries: 2, BreakerFailures: 5, BreakerCooldown: time.Minute})
+
+	emb, err := p.Embed(context.Background(), "text", EmbeddingDocument)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if len(emb) != 2 || stub.calls != 3 {
+		t.Fatalf("Embed = %v после %d вызовов, want эмбеддинг после 3", emb, stub.calls)
+	}
+}
+
+func TestResilientProviderDoesNotRetryBadRequest(t *testing.T) {
+	stub := &embedStub{errs: []error{errors.New("bad response. Http Status 400 message bad")}}
+	p := NewResilientProvider(nil, stub, Policy{MaxRetries: 3})
+
+	if _, err := p.Embed(context.Background(), "text", EmbeddingDocument); err == nil {
+		t.Fatal("Embed без ошибки")
+	}
+	if stub.calls != 1 {
+		t.Fatalf("ошибка запроса повторена: %d вызовов", stub.calls)
+	}
+}
+
+func TestResilientProviderOpensBreaker(t *testing.T) {
+	down := errors.New("bad response. Http Status 503 unavailable")
+	stub := &embedStub{errs: []error{down, down, down}}
+	p := NewResilientProvider(nil, stub, Policy{BreakerFailures: 2, BreakerCooldown: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.Embed(context.Background(), "text", EmbeddingDocument); !errors.Is(err, ErrAIUnavailable) {
+			t.Fatalf("Embed #%d: %v, want ErrAIUnavailable", i+1, err)
+		}
+	}
+	if _, err := p.Embed(context.Background(), "text", EmbeddingDocument); !errors.Is(err, ErrAIUnavailable) {
+		t.Fatalf("Embed при разомкнутом breaker: %v", err)
+	}
+	if stub.calls != 2 {
+		t.Fatalf("при разомкнутом breaker провайдер вызван: %d вызовов", stub.calls)
+	}
+}
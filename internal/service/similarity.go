@@ -0,0 +1,49 @@
+package service
+
+import "math"
+
+// MeanEmbedding возвращает нормированное среднее векторов — эмбеддинг заметки целиком по эмбеддингам её фрагментов
+func MeanEmbedding(vectors [][]float64) []float64 {
+	if len(vectors) == 0 {
+		return nil
+	}
+	mean := make([]float64, len(vectors[0]))
+	for _, v := range vectors {
+		for i := range mean {
+			if i < len(v) {
+				mean[i] += v[i]
+			}
+		}
+	}
+	var norm float64
+	for _, x := range mean {
+		norm += x * x
+	}
+	if norm == 0 {
+		return mean
+	}
+	norm = math.Sqrt(norm)
+	for i := range mean {
+		mean[i] /= norm
+	}
+	return mean
+}
+
+// CosineSimilarity возвращает косинусное сходство двух векторов.
+// Для векторов разной длины или нулевых векторов возвращается 0.
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
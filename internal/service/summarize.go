@@ -1,12 +1,9 @@
 package service
 
 import (
-	"NeuroNest/internal/config"
 	"context"
 	"fmt"
 	"strings"
-
-	"github.com/sheeiavellie/go-yandexgpt"
 )
 
 func SummarizeText(text string) (string, error) {
@@ -14,37 +11,41 @@ func SummarizeText(text string) (string, error) {
 		return text, nil
 	}
 
-	ctx := context.Background()
-	client := GetClient(ctx)
+	llm, err := DefaultCompleter()
+	if err != nil {
+		return "", err
+	}
+	return llm.Complete(context.Background(), summarizeRequest(text))
+}
 
-	modelURI := yandexgpt.MakeModelURI(config.CatalogID, yandexgpt.YandexGPT4Model)
+// SummarizeTextStream работает как SummarizeText, но передаёт текст резюме в onDelta по мере генерации
+func SummarizeTextStream(ctx context.Context, text string, onDelta func(string) error) (string, error) {
+	if len(text) < 200 || wordCount(text) < 50 {
+		return text, onDelta(text)
+	}
 
-	req := yandexgpt.YandexGPTRequest{
-		ModelURI: modelURI,
-		CompletionOptions: yandexgpt.YandexGPTCompletionOptions{
-			Stream:      false,
-			Temperature: 0.3,
-			MaxTokens:   1024,
-		},
-		Messages: []yandexgpt.YandexGPTMessage{
+	llm, err := DefaultCompleter()
+	if err != nil {
+		return "", err
+	}
+	return llm.CompleteStream(ctx, summarizeRequest(text), onDelta)
+}
+
+func summarizeRequest(text string) CompletionRequest {
+	return CompletionRequest{
+		Temperature: 0.3,
+		MaxTokens:   1024,
+		Messages: []Message{
 			{
-				Role: yandexgpt.YandexGPTMessageRoleSystem,
+				Role: RoleSystem,
 				Text: "Вы — помощник, который кратко и точно суммирует предоставленный текст.",
 			},
 			{
-				Role: yandexgpt.YandexGPTMessageRoleUser,
+				Role: RoleUser,
 				Text: fmt.Sprintf("Пожалуйста, сделай краткое резюме этого текста (сжатие должно быть от 50%% до 80%%):\n\n%s", text),
 			},
 		},
 	}
-
-	resp, err := client.GetCompletion(ctx, req)
-	if err != nil {
-		return "", err
-	}
-
-	summary := resp.Result.Alternatives[0].Message.Text
-	return summary, nil
 }
 
 func wordCount(text string) int {
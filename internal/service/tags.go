@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TagCandidate — тег, предложенный моделью, с её уверенностью 0..1
+type TagCandidate struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+// maxNewTagSuggestions сколько новых тегов (которых ещё нет у пользователя) может предложить модель
+const maxNewTagSuggestions = 3
+
+// SuggestTags просит модель подобрать теги для заметки: в первую очередь из существующих тегов пользователя,
+// а также до maxNewTagSuggestions новых названий
+func SuggestTags(title, content string, existing []string, limit int) ([]TagCandidate, error) {
+	llm, err := DefaultCompleter()
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := llm.Complete(context.Background(), tagsRequest(title, content, existing, limit))
+	if err != nil {
+		return nil, err
+	}
+	return parseTagCandidates(answer)
+}
+
+func tagsRequest(title, content string, existing []string, limit int) CompletionRequest {
+	text := []rune(content)
+	if len(text) > maxContextNoteLen {
+		text = text[:maxContextNoteLen]
+	}
+
+	var b strings.Builder
+	if len(existing) > 0 {
+		fmt.Fprintf(&b, "Существующие теги пользователя: %s\n\n", strings.Join(existing, ", "))
+	} else {
+		b.WriteString("У пользователя пока нет тегов.\n\n")
+	}
+	fmt.Fprintf(&b, "Заметка «%s»:\n%s", title, string(text))
+
+	return CompletionRequest{
+		Temperature: 0.1,
+		MaxTokens:   500,
+		Messages: []Message{
+			{
+				Role: RoleSystem,
+				Text: fmt.Sprintf("Вы подбираете теги для заметок. Выберите до %d подходящих тегов, в первую очередь из существующих тегов пользователя, "+
+					"их названия пишите точно как в списке. Можно предложить до %d новых коротких тегов, если существующие не подходят. "+
+					`Ответьте только JSON-массивом вида [{"name": "тег", "confidence": 0.8}], где confidence — уверенность от 0 до 1.`,
+					limit, maxNewTagSuggestions),
+			},
+			{Role: RoleUser, Text: b.String()},
+		},
+	}
+}
+
+// parseTagCandidates достаёт JSON-массив из ответа модели (модель может обернуть его в текст или markdown)
+func parseTagCandidates(answer string) ([]TagCandidate, error) {
+	start := strings.Index(answer, "[")
+	end := strings.LastIndex(answer, "]")
+	if start < 0 || end < start {
+		return nil, errors.New("model answer does not contain a JSON array")
+	}
+
+	var raw []TagCandidate
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("parse model answer: %w", err)
+	}
+
+	candidates := make([]TagCandidate, 0, len(raw))
+	for _, c := range raw {
+		c.Name = strings.TrimSpace(c.Name)
+		if c.Name == "" {
+			continue
+		}
+		if c.Confidence < 0 {
+			c.Confidence = 0
+		}
+		if c.Confidence > 1 {
+			c.Confidence = 1
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sheeiavellie/go-yandexgpt"
+)
+
+// yandexCompletionURL тот же метод completion, что использует клиент go-yandexgpt.
+// Для stream: true он вызывается напрямую: ответ приходит последовательностью JSON-объектов
+const yandexCompletionURL = "https://llm.api.cloud.yandex.net/foundationModels/v1/completion"
+
+// YandexProvider — генерация текста и эмбеддинги через YandexGPT
+type YandexProvider struct {
+	Tokens     TokenSource
+	CatalogID  string
+	HTTPClient *http.Client
+}
+
+func NewYandexProvider(tokens TokenSource, catalogID string) (*YandexProvider, error) {
+	if catalogID == "" {
+		return nil, errors.New("catalog ID is not set")
+	}
+	return &YandexProvider{Tokens: tokens, CatalogID: catalogID, HTTPClient: &http.Client{}}, nil
+}
+
+// client создаёт клиент go-yandexgpt с актуальным IAM-токеном
+func (p *YandexProvider) client(ctx context.Context) (*yandexgpt.YandexGPTClient, error) {
+	token, err := p.Tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return yandexgpt.NewYandexGPTClientWithIAMToken(token), nil
+}
+
+func (p *YandexProvider) request(req CompletionRequest, stream bool) yandexgpt.YandexGPTRequest {
+	messages := make([]yandexgpt.YandexGPTMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		role := yandexgpt.YandexGPTMessageRoleUser
+		switch m.Role {
+		case RoleSystem:
+			role = yandexgpt.YandexGPTMessageRoleSystem
+		case RoleAssistant:
+			role = yandexgpt.YandexGPTMessageRoleAssistant
+		}
+		messages = append(messages, yandexgpt.YandexGPTMessage{Role: role, Text: m.Text})
+	}
+
+	return yandexgpt.YandexGPTRequest{
+		ModelURI: yandexgpt.MakeModelURI(p.CatalogID, yandexgpt.YandexGPT4Model),
+		CompletionOptions: yandexgpt.YandexGPTCompletionOptions{
+			Stream:      stream,
+			Temperature: req.Temperature,
+			MaxTokens:   req.MaxTokens,
+		},
+		Messages: messages,
+	}
+}
+
+func (p *YandexProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.GetCompletion(ctx, p.request(req, false))
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Result.Alternatives) == 0 {
+		return "", errors.New("empty completion response")
+	}
+	return resp.Result.Alternatives[0].Message.Text, nil
+}
+
+// CompleteStream выполняет запрос с потоковой выдачей.
+// YandexGPT в каждом сообщении присылает весь накопленный текст, поэтому дельта вычисляется относительно предыдущего.
+func (p *YandexProvider) CompleteStream(ctx context.Context, req CompletionRequest, onDelta func(string) error) (string, error) {
+	token, err := p.Tokens.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(p.request(req, true))
+	if err != nil {
+		return "", err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, yandexCompletionURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := p.HTTPClient.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("bad response. Http Status %d %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var text string
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var chunk yandexgpt.YandexGPTResponse
+		if err := dec.Decode(&chunk); err == io.EOF {
+			break
+		} else if err != nil {
+			return text, err
+		}
+		if len(chunk.Result.Alternatives) == 0 {
+			continue
+		}
+
+		current := chunk.Result.Alternatives[0].Message.Text
+		if !strings.HasPrefix(current, text) {
+			// На случай, если модель прислала не накопленный текст, а только новый фрагмент
+			current = text + current
+		}
+		if delta := current[len(text):]; delta != "" {
+			if err := onDelta(delta); err != nil {
+				return current, err
+			}
+		}
+		text = current
+	}
+
+	if text == "" {
+		return "", errors.New("empty completion response")
+	}
+	return text, nil
+}
+
+// ModelURI — text-search-doc для документов и text-search-query для запросов
+func (p *YandexProvider) ModelURI(kind EmbeddingKind) string {
+	if kind == EmbeddingQuery {
+		return yandexgpt.MakeEmbModelURI(p.CatalogID, yandexgpt.TextSearchQuery)
+	}
+	return yandexgpt.MakeEmbModelURI(p.CatalogID, yandexgpt.TextSearchDoc)
+}
+
+func (p *YandexProvider) Embed(ctx context.Context, text string, kind EmbeddingKind) ([]float64, error) {
+	req := yandexgpt.YandexGPTEmbeddingsRequest{
+		ModelURI: p.ModelURI(kind),
+		Text:     text,
+	}
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.GetEmbedding(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Embedding, nil
+}
@@ -0,0 +1,154 @@
+// Package textchunk делит длинный текст на перекрывающиеся фрагменты по границам абзацев и предложений.
+package textchunk
+
+import "unicode"
+
+// Chunk — фрагмент текста. Start и End — смещения в символах (рунах) исходного текста, End не включается
+type Chunk struct {
+	Index int
+	Start int
+	End   int
+	Text  string
+}
+
+// unit — предложение или строка текста; paragraph = true, если с неё начинается абзац
+type unit struct {
+	start, end int
+	paragraph  bool
+}
+
+// Split делит текст на фрагменты не длиннее size символов. Соседние фрагменты перекрываются
+// последними предложениями предыдущего фрагмента общей длиной до overlap символов.
+// Фрагмент по возможности заканчивается на границе абзаца, а предложение длиннее size режется по пробелу
+func Split(text string, size, overlap int) []Chunk {
+	runes := []rune(text)
+	if size <= 0 {
+		size = len(runes)
+	}
+	if overlap >= size {
+		overlap = size / 4
+	}
+
+	units := splitUnits(runes, size)
+	if len(units) == 0 {
+		return nil
+	}
+
+	var chunks []Chunk
+	first, prevLast := 0, -1
+	for first < len(units) {
+		// Набираем предложения, пока фрагмент помещается в size
+		last := first
+		for last+1 < len(units) {
+			next := units[last+1]
+			if next.end-units[first].start > size {
+				break
+			}
+			// Почти полный фрагмент лучше закончить на границе абзаца, чем разрывать следующий абзац.
+			// Но только после хотя бы одного нового предложения: при большом overlap хвост предыдущего
+			// фрагмента сам занимает больше ¾ size, и без этого фрагмент целиком повторял бы предыдущий
+			if next.paragraph && last > prevLast && units[last].end-units[first].start >= size*3/4 {
+				break
+			}
+			last++
+		}
+
+		start, end := units[first].start, units[last].end
+		chunks = append(chunks, Chunk{
+			Index: len(chunks),
+			Start: start,
+			End:   end,
+			Text:  string(runes[start:end]),
+		})
+		if last == len(units)-1 {
+			break
+		}
+
+		// Следующий фрагмент начинается с хвоста текущего длиной до overlap,
+		// но так, чтобы в него поместилось хотя бы одно новое предложение
+		next := last + 1
+		for next-1 > first && end-units[next-1].start <= overlap && units[last+1].end-units[next-1].start <= size {
+			next--
+		}
+		first, prevLast = next, last
+	}
+	return chunks
+}
+
+// splitUnits находит предложения и строки текста без окружающих пробелов.
+// Предложение, которое длиннее size, делится на части по последнему пробелу перед границей
+func splitUnits(runes []rune, size int) []unit {
+	var units []unit
+	add := func(start, end int, paragraph bool) {
+		for start < end && unicode.IsSpace(runes[start]) {
+			start++
+		}
+		for end > start && unicode.IsSpace(runes[end-1]) {
+			end--
+		}
+		if start == end {
+			return
+		}
+		for end-start > size {
+			cut := start + size
+			for i := cut; i > start+size/2; i-- {
+				if unicode.IsSpace(runes[i]) {
+					cut = i
+					break
+				}
+			}
+			pieceEnd := cut
+			for pieceEnd > start && unicode.IsSpace(runes[pieceEnd-1]) {
+				pieceEnd--
+			}
+			units = append(units, unit{start: start, end: pieceEnd, paragraph: paragraph})
+			paragraph = false
+			start = cut
+			for start < end && unicode.IsSpace(runes[start]) {
+				start++
+			}
+		}
+		units = append(units, unit{start: start, end: end, paragraph: paragraph})
+	}
+
+	start, lineStart := 0, 0
+	paragraph := true
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\n':
+			before := len(units)
+			add(start, i, paragraph)
+			if len(units) > before {
+				paragraph = false
+			}
+			// Пустая строка (или строка из пробелов) начинает новый абзац
+			if blank(runes[lineStart:i]) {
+				paragraph = true
+			}
+			start, lineStart = i+1, i+1
+		case isSentenceEnd(r) && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])):
+			before := len(units)
+			add(start, i+1, paragraph)
+			if len(units) > before {
+				paragraph = false
+			}
+			start = i + 1
+		}
+	}
+	add(start, len(runes), paragraph)
+	return units
+}
+
+func isSentenceEnd(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+func blank(runes []rune) bool {
+	for _, r := range runes {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
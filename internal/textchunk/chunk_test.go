@@ -0,0 +1,173 @@
+package textchunk
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		size    int
+		overlap int
+		want    []Chunk
+	}{
+		{
+			name: "пустой текст",
+			text: "",
+			size: 10,
+		},
+		{
+			name: "только пробелы",
+			text: " \n\t ",
+			size: 10,
+		},
+		{
+			name: "помещается целиком",
+			text: "  Один. Два.  ",
+			size: 100,
+			want: []Chunk{{Index: 0, Start: 2, End: 12, Text: "Один. Два."}},
+		},
+		{
+			name: "без ограничения длины",
+			text: "Один. Два.",
+			size: 0,
+			want: []Chunk{{Index: 0, Start: 0, End: 10, Text: "Один. Два."}},
+		},
+		{
+			name:    "перекрытие последним предложением",
+			text:    "Aaaa. Bbbb. Cccc.",
+			size:    11,
+			overlap: 5,
+			want: []Chunk{
+				{Index: 0, Start: 0, End: 11, Text: "Aaaa. Bbbb."},
+				{Index: 1, Start: 6, End: 17, Text: "Bbbb. Cccc."},
+			},
+		},
+		{
+			name:    "без перекрытия",
+			text:    "Aaaa. Bbbb. Cccc.",
+			size:    11,
+			overlap: 0,
+			want: []Chunk{
+				{Index: 0, Start: 0, End: 11, Text: "Aaaa. Bbbb."},
+				{Index: 1, Start: 12, End: 17, Text: "Cccc."},
+			},
+		},
+		{
+			name: "длинное предложение режется по пробелу",
+			text: "aaaa bbbb cccc",
+			size: 10,
+			want: []Chunk{
+				{Index: 0, Start: 0, End: 9, Text: "aaaa bbbb"},
+				{Index: 1, Start: 10, End: 14, Text: "cccc"},
+			},
+		},
+		{
+			name:    "конец абзаца",
+			text:    "Aaaaaaa. Bbbbbbbbb.\n\nC. Ddd.",
+			size:    24,
+			overlap: 0,
+			want: []Chunk{
+				{Index: 0, Start: 0, End: 19, Text: "Aaaaaaa. Bbbbbbbbb."},
+				{Index: 1, Start: 21, End: 28, Text: "C. Ddd."},
+			},
+		},
+		{
+			name:    "смещения в символах, а не в байтах",
+			text:    "Привет. Мир.",
+			size:    7,
+			overlap: 0,
+			want: []Chunk{
+				{Index: 0, Start: 0, End: 7, Text: "Привет."},
+				{Index: 1, Start: 8, End: 12, Text: "Мир."},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Split(tt.text, tt.size, tt.overlap)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Split() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("chunk %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestSplitLargeOverlapNoRedundantChunks — при перекрытии больше ¾ size правило конца абзаца
+// не должно давать фрагмент, целиком лежащий в предыдущем
+func TestSplitLargeOverlapNoRedundantChunks(t *testing.T) {
+	text := "Aaaaaaaaa. Bbbbbbbbb. Ccccccccc.\n\nDdd. Eee. Fff."
+	checkChunks(t, text, 40, 35, Split(text, 40, 35))
+}
+
+func FuzzSplit(f *testing.F) {
+	f.Add("Первое предложение. Второе!\n\nНовый абзац? Да… Конец.", 20, 5)
+	f.Add("Aaaaaaaaa. Bbbbbbbbb. Ccccccccc.\n\nDdd. Eee. Fff.", 40, 35)
+	f.Add("слово слово слово слово слово", 8, 3)
+	f.Add("a.\n\nb.\n\nc.\n\nd.", 4, 3)
+	f.Add("0000  0", 6, 3)
+	f.Fuzz(func(t *testing.T, text string, size, overlap int) {
+		if size < 0 || size > 500 || overlap < 0 {
+			t.Skip()
+		}
+		checkChunks(t, text, size, overlap, Split(text, size, overlap))
+	})
+}
+
+// checkChunks проверяет инварианты Split: смещения и текст фрагментов, лимит длины,
+// размер перекрытия, отсутствие лишних фрагментов и покрытие всего непробельного текста
+func checkChunks(t *testing.T, text string, size, overlap int, chunks []Chunk) {
+	t.Helper()
+	runes := []rune(text)
+	if size <= 0 {
+		size = len(runes)
+	}
+	if overlap >= size {
+		overlap = size / 4
+	}
+
+	covered := make([]bool, len(runes))
+	for i, c := range chunks {
+		if c.Index != i {
+			t.Fatalf("chunk %d: Index = %d", i, c.Index)
+		}
+		if c.Start < 0 || c.End > len(runes) || c.Start >= c.End {
+			t.Fatalf("chunk %d: неверные смещения [%d, %d) при длине %d", i, c.Start, c.End, len(runes))
+		}
+		if c.Text != string(runes[c.Start:c.End]) {
+			t.Fatalf("chunk %d: Text %q не совпадает с текстом по смещениям", i, c.Text)
+		}
+		if strings.TrimSpace(c.Text) != c.Text {
+			t.Fatalf("chunk %d: %q с пробелами по краям", i, c.Text)
+		}
+		if c.End-c.Start > size {
+			t.Fatalf("chunk %d: длина %d больше size %d", i, c.End-c.Start, size)
+		}
+		if i > 0 {
+			prev := chunks[i-1]
+			if c.Start < prev.Start || c.End <= prev.End {
+				t.Fatalf("chunk %d [%d, %d) не продвигается после [%d, %d)", i, c.Start, c.End, prev.Start, prev.End)
+			}
+			if c.Start < prev.End && prev.End-c.Start > overlap {
+				t.Fatalf("chunk %d: перекрытие %d больше overlap %d", i, prev.End-c.Start, overlap)
+			}
+		}
+		for j := c.Start; j < c.End; j++ {
+			covered[j] = true
+		}
+	}
+	for i, r := range runes {
+		if !covered[i] && !unicode.IsSpace(r) {
+			t.Fatalf("символ %d %q не попал ни в один фрагмент", i, r)
+		}
+	}
+}
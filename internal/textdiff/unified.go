@@ -0,0 +1,195 @@
+// Package textdiff строит построчный unified diff двух текстов (алгоритм Майерса).
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines количество неизменённых строк вокруг каждого изменения
+const contextLines = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// edit — одна операция сценария правки. a и b — позиции в исходном и новом тексте
+type edit struct {
+	kind opKind
+	a, b int
+}
+
+// Unified возвращает unified diff между a и b с заголовками fromName и toName.
+// Для одинаковых текстов возвращается пустая строка.
+func Unified(fromName, toName, a, b string) string {
+	aLines, bLines := splitLines(a), splitLines(b)
+	edits := diffLines(aLines, bLines)
+
+	var out strings.Builder
+	for _, h := range hunks(edits) {
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		writeHunk(&out, edits[h[0]:h[1]], aLines, bLines)
+	}
+	return out.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines находит кратчайший сценарий правки a -> b
+func diffLines(a, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	// trace[d] — значения v перед шагом d, только для диагоналей -d-1..d+1, которые читает восстановление пути:
+	// полная копия v на каждом шаге заняла бы O((n+m)·D) памяти
+	var trace [][]int
+
+search:
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, 2*d+3)
+		copy(snapshot, v[offset-d-1:offset+d+2])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Восстанавливаем путь от конца к началу
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v, vOffset := trace[d], d+1
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[vOffset+k-1] < v[vOffset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[vOffset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			edits = append(edits, edit{kind: opEqual, a: x - 1, b: y - 1})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{kind: opInsert, a: x, b: y - 1})
+			} else {
+				edits = append(edits, edit{kind: opDelete, a: x - 1, b: y})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// hunks группирует изменения в блоки [start, end) с контекстом вокруг
+func hunks(edits []edit) [][2]int {
+	var result [][2]int
+	prevEnd := 0
+	for i := 0; i < len(edits); {
+		for i < len(edits) && edits[i].kind == opEqual {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		// Расширяем блок, пока следующее изменение ближе 2*contextLines строк
+		lastChange := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].kind != opEqual {
+				lastChange = j
+			} else if j-lastChange > 2*contextLines {
+				break
+			}
+		}
+
+		start := i - contextLines
+		if start < prevEnd {
+			start = prevEnd
+		}
+		end := lastChange + contextLines + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+		result = append(result, [2]int{start, end})
+		prevEnd = end
+		i = end
+	}
+	return result
+}
+
+func writeHunk(out *strings.Builder, edits []edit, a, b []string) {
+	var aLen, bLen int
+	for _, e := range edits {
+		if e.kind != opInsert {
+			aLen++
+		}
+		if e.kind != opDelete {
+			bLen++
+		}
+	}
+
+	aStart, bStart := edits[0].a, edits[0].b
+	if aLen > 0 {
+		aStart++
+	}
+	if bLen > 0 {
+		bStart++
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+
+	for _, e := range edits {
+		switch e.kind {
+		case opEqual:
+			out.WriteString(" " + a[e.a] + "\n")
+		case opDelete:
+			out.WriteString("-" + a[e.a] + "\n")
+		case opInsert:
+			out.WriteString("+" + b[e.b] + "\n")
+		}
+	}
+}
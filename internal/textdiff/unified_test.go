@@ -0,0 +1,148 @@
+package textdiff
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "одинаковые тексты",
+			a:    "a\nb\nc\n",
+			b:    "a\nb\nc\n",
+			want: "",
+		},
+		{
+			name: "оба пустые",
+			want: "",
+		},
+		{
+			name: "из пустого",
+			b:    "a\nb\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "в пустой",
+			a:    "a\nb\n",
+			want: "--- a\n+++ b\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name: "замена строки",
+			a:    "a\nb\nc\n",
+			b:    "a\nx\nc\n",
+			want: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name: "дописана строка без перевода строки в конце",
+			a:    "a\nb",
+			b:    "a\nb\nc",
+			want: "--- a\n+++ b\n@@ -1,2 +1,3 @@\n a\n b\n+c\n",
+		},
+		{
+			name: "далёкие изменения — разные блоки",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n",
+			b:    "1\nX\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\nY\n15\n",
+			want: "--- a\n+++ b\n@@ -1,5 +1,5 @@\n 1\n-2\n+X\n 3\n 4\n 5\n" +
+				"@@ -11,5 +11,5 @@\n 11\n 12\n 13\n-14\n+Y\n 15\n",
+		},
+		{
+			name: "близкие изменения — один блок",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:    "1\nX\n3\n4\n5\n6\nY\n8\n",
+			want: "--- a\n+++ b\n@@ -1,8 +1,8 @@\n 1\n-2\n+X\n 3\n 4\n 5\n 6\n-7\n+Y\n 8\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("a", "b", tt.a, tt.b); got != tt.want {
+				t.Errorf("Unified() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDiffLinesMinimal сверяет сценарий правки со случайными текстами: он должен превращать a в b
+// и быть кратчайшим — число вставок и удалений равно n+m-2·LCS
+func TestDiffLinesMinimal(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rnd.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rnd.Intn(3)))
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := randomLines(), randomLines()
+		edits := diffLines(a, b)
+
+		var got []string
+		changes := 0
+		for _, e := range edits {
+			switch e.kind {
+			case opEqual:
+				if a[e.a] != b[e.b] {
+					t.Fatalf("diffLines(%q, %q): равные строки %d и %d различаются", a, b, e.a, e.b)
+				}
+				got = append(got, a[e.a])
+			case opInsert:
+				got = append(got, b[e.b])
+				changes++
+			case opDelete:
+				changes++
+			}
+		}
+		if strings.Join(got, "\n") != strings.Join(b, "\n") {
+			t.Fatalf("diffLines(%q, %q) собирает %q", a, b, got)
+		}
+		if want := len(a) + len(b) - 2*lcs(a, b); changes != want {
+			t.Fatalf("diffLines(%q, %q): %d правок, кратчайший сценарий — %d", a, b, changes, want)
+		}
+	}
+}
+
+func TestDiffLinesLongText(t *testing.T) {
+	a := make([]string, 5000)
+	for i := range a {
+		a[i] = strings.Repeat("x", i%7)
+	}
+	b := append([]string{"начало"}, a...)
+	b[2500] = "середина"
+	b = append(b, "конец")
+
+	edits := diffLines(a, b)
+	changes := 0
+	for _, e := range edits {
+		if e.kind != opEqual {
+			changes++
+		}
+	}
+	if changes != 4 {
+		t.Errorf("diffLines: %d правок, want 4", changes)
+	}
+}
+
+// lcs длина наибольшей общей подпоследовательности строк
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
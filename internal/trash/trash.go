@@ -0,0 +1,114 @@
+// Package trash окончательно удаляет заметки из корзины: вручную и по истечении срока хранения.
+package trash
+
+import (
+	"NeuroNest/internal/config"
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Purge окончательно удаляет заметку вместе со связями, ревизиями, AI-задачами, фрагментами, вложениями и их файлами
+func Purge(note models.Note) error {
+	var attachments []models.Attachment
+	if err := db.DB.Unscoped().Where("note_id = ?", note.ID).Find(&attachments).Error; err != nil {
+		return err
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM note_tags WHERE note_id = ?", note.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("note_id = ?", note.ID).Delete(&models.Attachment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("note_id = ?", note.ID).Delete(&models.NoteRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("note_id = ?", note.ID).Delete(&models.AIJob{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("note_id = ?", note.ID).Delete(&models.NoteChunk{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("note_id = ? OR related_id = ?", note.ID, note.ID).Delete(&models.NoteRelation{}).Error; err != nil {
+			return err
+		}
+		// Ссылки других заметок пользователя на удаляемую заметку
+		if err := tx.Exec("UPDATE notes SET related_ids = array_remove(related_ids, ?) WHERE user_id = ? AND ? = ANY(related_ids)",
+			note.ID, note.UserID, note.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE notes SET duplicate_ids = array_remove(duplicate_ids, ?) WHERE user_id = ? AND ? = ANY(duplicate_ids)",
+			note.ID, note.UserID, note.ID).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.Note{}, note.ID).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// Файлы удаляем только после фиксации транзакции, чтобы не потерять их при откате
+	for _, attachment := range attachments {
+		filePath := filepath.Join(config.UploadsPath, "attachments", filepath.Base(attachment.FileURL))
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			// Логируем ошибку, но продолжаем выполнение
+			fmt.Printf("Error deleting file %s: %v\n", filePath, err)
+		}
+	}
+	return nil
+}
+
+// PurgeAt возвращает момент, после которого заметка будет удалена из корзины автоматически
+func PurgeAt(deletedAt time.Time) time.Time {
+	return deletedAt.Add(retention())
+}
+
+func retention() time.Duration {
+	return time.Duration(config.TrashRetentionDays) * 24 * time.Hour
+}
+
+// StartPurger запускает фоновую очистку корзины с заданным интервалом.
+// Нулевой интервал отключает очистку
+func StartPurger(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			purgeExpired()
+			<-ticker.C
+		}
+	}()
+}
+
+// purgeExpired удаляет заметки, пролежавшие в корзине дольше срока хранения
+func purgeExpired() {
+	var notes []models.Note
+	cutoff := time.Now().Add(-retention())
+	if err := db.DB.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Find(&notes).Error; err != nil {
+		log.Printf("Ошибка при поиске заметок для очистки корзины: %v", err)
+		return
+	}
+
+	purged := 0
+	for _, note := range notes {
+		if err := Purge(note); err != nil {
+			log.Printf("Ошибка при удалении заметки %d из корзины: %v", note.ID, err)
+			continue
+		}
+		purged++
+	}
+	if purged > 0 {
+		log.Printf("Очистка корзины: удалено заметок %d", purged)
+	}
+}
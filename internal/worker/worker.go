@@ -0,0 +1,202 @@
+// Package worker выполняет фоновые AI-задачи (эмбеддинги, резюме) из очереди в таблице ai_jobs.
+// Неудачные задачи повторяются с экспоненциальной задержкой, после исчерпания попыток переходят в состояние dead.
+package worker
+
+import (
+	"NeuroNest/internal/config"
+	"NeuroNest/internal/db"
+	"NeuroNest/internal/models"
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Handler выполняет задачу одного типа. Возвращённая ошибка приводит к повтору задачи
+type Handler func(ctx context.Context, job models.AIJob) error
+
+// staleAfter — через сколько задача в состоянии running считается зависшей (воркер упал) и берётся повторно
+const staleAfter = 10 * time.Minute
+
+// maxBackoff ограничивает задержку перед повтором
+const maxBackoff = time.Hour
+
+// noteStatusColumns колонка заметки, в которой отражается состояние задачи каждого типа
+var noteStatusColumns = map[string]string{
+	models.AIJobEmbed:     "embedding_status",
+	models.AIJobSummarize: "summary_status",
+}
+
+var (
+	mu       sync.RWMutex
+	handlers = map[string]Handler{}
+)
+
+// Register задаёт обработчик для задач указанного типа
+func Register(jobType string, h Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers[jobType] = h
+}
+
+// Enqueue ставит задачу для заметки в очередь в рамках транзакции tx.
+// Если такая задача уже ждёт выполнения, новая не создаётся — ожидающая запускается как можно скорее
+func Enqueue(tx *gorm.DB, jobType string, noteID, userID uint) error {
+	now := time.Now()
+	res := tx.Model(&models.AIJob{}).
+		Where("type = ? AND note_id = ? AND status = ?", jobType, noteID, models.AIJobPending).
+		Updates(map[string]interface{}{"run_at": now, "attempts": 0, "last_error": ""})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		job := models.AIJob{
+			Type:        jobType,
+			NoteID:      noteID,
+			UserID:      userID,
+			Status:      models.AIJobPending,
+			RunAt:       now,
+			MaxAttempts: config.AIJobMaxAttempts,
+		}
+		if err := tx.Create(&job).Error; err != nil {
+			return err
+		}
+	}
+	return setNoteStatus(tx, jobType, noteID, models.AIStatusPending)
+}
+
+// Start запускает пул из n воркеров, опрашивающих очередь с интервалом poll.
+// n <= 0 отключает обработку очереди
+func Start(n int, poll time.Duration) {
+	if n <= 0 {
+		return
+	}
+	for i := 0; i < n; i++ {
+		go loop(poll)
+	}
+	log.Printf("Запущено воркеров AI-очереди: %d", n)
+}
+
+func loop(poll time.Duration) {
+	for {
+		job, ok, err := claim()
+		if err != nil {
+			log.Printf("Ошибка при получении задачи из очереди: %v", err)
+		}
+		if !ok {
+			time.Sleep(poll)
+			continue
+		}
+		finish(job, run(job))
+	}
+}
+
+// claim атомарно берёт в работу ближайшую готовую задачу.
+// FOR UPDATE SKIP LOCKED позволяет нескольким воркерам (и экземплярам сервера) не мешать друг другу
+func claim() (models.AIJob, bool, error) {
+	now := time.Now()
+	var jobs []models.AIJob
+	err := db.DB.Raw(`UPDATE ai_jobs SET status = ?, attempts = attempts + 1, locked_at = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM ai_jobs
+			WHERE deleted_at IS NULL
+			AND ((status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?))
+			ORDER BY run_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.AIJobRunning, now, now,
+		models.AIJobPending, now, models.AIJobRunning, now.Add(-staleAfter),
+	).Scan(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return models.AIJob{}, false, err
+	}
+
+	job := jobs[0]
+	if err := setNoteStatus(db.DB, job.Type, job.NoteID, models.AIStatusProcessing); err != nil {
+		log.Printf("Ошибка обновления статуса заметки %d: %v", job.NoteID, err)
+	}
+	return job, true, nil
+}
+
+// run вызывает обработчик задачи, превращая панику в обычную ошибку
+func run(job models.AIJob) (err error) {
+	mu.RLock()
+	h, ok := handlers[job.Type]
+	mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("нет обработчика для задачи типа %q", job.Type)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("паника в обработчике задачи: %v", r)
+		}
+	}()
+	return h(context.Background(), job)
+}
+
+// finish сохраняет результат выполнения: успех, повтор с задержкой или dead-letter
+func finish(job models.AIJob, runErr error) {
+	now := time.Now()
+	updates := map[string]interface{}{"locked_at": nil}
+	noteStatus := models.AIStatusReady
+
+	switch {
+	case runErr == nil:
+		updates["status"] = models.AIJobDone
+		updates["last_error"] = ""
+		updates["finished_at"] = now
+	case job.Attempts >= job.MaxAttempts:
+		updates["status"] = models.AIJobDead
+		updates["last_error"] = runErr.Error()
+		updates["finished_at"] = now
+		noteStatus = models.AIStatusFailed
+		log.Printf("Задача %d (%s, заметка %d) не выполнена за %d попыток: %v", job.ID, job.Type, job.NoteID, job.Attempts, runErr)
+	default:
+		updates["status"] = models.AIJobPending
+		updates["last_error"] = runErr.Error()
+		updates["run_at"] = now.Add(backoff(job.Attempts))
+		noteStatus = models.AIStatusPending
+	}
+
+	if err := db.DB.Model(&models.AIJob{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		log.Printf("Ошибка сохранения результата задачи %d: %v", job.ID, err)
+		return
+	}
+	if err := setNoteStatus(db.DB, job.Type, job.NoteID, noteStatus); err != nil {
+		log.Printf("Ошибка обновления статуса заметки %d: %v", job.NoteID, err)
+	}
+}
+
+// backoff возвращает задержку перед следующей попыткой: база удваивается с каждой попыткой, плюс до 20% случайного разброса
+func backoff(attempts int) time.Duration {
+	d := time.Duration(config.AIJobBackoffSeconds) * time.Second
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/5+1))
+}
+
+// setNoteStatus отражает состояние задачи в заметке. Пока для заметки ждёт более новая задача того же типа,
+// итоговый статус (ready, failed) не записывается, чтобы не скрыть её
+func setNoteStatus(tx *gorm.DB, jobType string, noteID uint, status string) error {
+	column, ok := noteStatusColumns[jobType]
+	if !ok {
+		return nil
+	}
+	q := tx.Model(&models.Note{}).Where("id = ?", noteID)
+	if status == models.AIStatusReady || status == models.AIStatusFailed {
+		q = q.Where("NOT EXISTS (SELECT 1 FROM ai_jobs WHERE ai_jobs.note_id = notes.id AND ai_jobs.type = ? AND ai_jobs.status = ? AND ai_jobs.deleted_at IS NULL)",
+			jobType, models.AIJobPending)
+	}
+	return q.UpdateColumn(column, status).Error
+}